@@ -0,0 +1,194 @@
+package mail_checker
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateAddress checks the syntax of an email address following RFC 5322
+// and RFC 5321, with the UTF-8 extensions of RFC 6531. It returns an
+// *AddressError describing the first problem found.
+func ValidateAddress(email string) error {
+	_, _, err := SplitAddress(email)
+	return err
+}
+
+// SplitAddress validates the email and returns its local part and domain.
+func SplitAddress(email string) (local string, domain string, err error) {
+	if email == "" {
+		return local, domain, &AddressError{Address: email, Reason: "address is empty"}
+	}
+	if !utf8.ValidString(email) {
+		return local, domain, &AddressError{Address: email, Reason: "address is not valid UTF-8"}
+	}
+	if len(email) > addressMaxLength {
+		return local, domain, &AddressError{Address: email, Reason: "address is longer than 254 octets"}
+	}
+
+	at := -1
+	if strings.HasPrefix(email, `"`) {
+		end := quotedStringEnd(email)
+		if end < 0 {
+			return local, domain, &AddressError{Address: email, Reason: "unterminated quoted local part"}
+		}
+		if end+1 < len(email) && email[end+1] == '@' {
+			at = end + 1
+		}
+	} else {
+		at = strings.IndexByte(email, '@')
+	}
+	if at < 0 {
+		return local, domain, &AddressError{Address: email, Reason: "missing @ after the local part"}
+	}
+	local, domain = email[:at], email[at+1:]
+
+	if reason := validateLocalPart(local); reason != "" {
+		return "", "", &AddressError{Address: email, Reason: reason}
+	}
+	if reason := validateDomain(domain); reason != "" {
+		return "", "", &AddressError{Address: email, Reason: reason}
+	}
+	return local, domain, nil
+}
+
+// quotedStringEnd returns the index of the quote closing the quoted string
+// that starts at s[0], or -1 when it is not terminated.
+func quotedStringEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func validateLocalPart(local string) string {
+	if local == "" {
+		return "local part is empty"
+	}
+	if len(local) > addressLocalMaxLength {
+		return "local part is longer than 64 octets"
+	}
+	if strings.HasPrefix(local, `"`) {
+		return validateQuotedLocalPart(local)
+	}
+
+	for _, atom := range strings.Split(local, ".") {
+		if atom == "" {
+			return "local part has an empty dot-atom"
+		}
+		for _, r := range atom {
+			if !isAtext(r) {
+				return "local part contains " + strconv.QuoteRune(r)
+			}
+		}
+	}
+	return ""
+}
+
+func validateQuotedLocalPart(local string) string {
+	if len(local) < 2 || quotedStringEnd(local) != len(local)-1 {
+		return "malformed quoted local part"
+	}
+	content := local[1 : len(local)-1]
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(content) || content[i+1] < 32 || content[i+1] > 126 {
+				return "invalid quoted-pair in local part"
+			}
+			i++
+		case c == '"':
+			return "unescaped quote in local part"
+		case c >= utf8.RuneSelf:
+			// UTF-8 is allowed in quoted strings by RFC 6531.
+		case c < 32 || c > 126:
+			return "control character in local part"
+		}
+	}
+	return ""
+}
+
+func validateDomain(domain string) string {
+	if domain == "" {
+		return "domain is empty"
+	}
+	if strings.HasPrefix(domain, "[") {
+		return validateDomainLiteral(domain)
+	}
+	if len(domain) > addressDomainMaxLength {
+		return "domain is longer than 253 octets"
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "domain has no top-level domain"
+	}
+	for _, label := range labels {
+		if label == "" {
+			return "domain has an empty label"
+		}
+		if len(label) > addressLabelMaxLength {
+			return "domain label is longer than 63 octets"
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return "domain label starts or ends with a hyphen"
+		}
+		for _, r := range label {
+			if !isLetterDigitHyphen(r) {
+				return "domain contains " + strconv.QuoteRune(r)
+			}
+		}
+	}
+
+	tld := labels[len(labels)-1]
+	if strings.Trim(tld, "0123456789") == "" {
+		return "top-level domain is numeric"
+	}
+	return ""
+}
+
+func validateDomainLiteral(domain string) string {
+	if !strings.HasSuffix(domain, "]") {
+		return "unterminated domain literal"
+	}
+	literal := domain[1 : len(domain)-1]
+	if v6, ok := strings.CutPrefix(literal, "IPv6:"); ok {
+		if ip := net.ParseIP(v6); ip == nil || !strings.Contains(v6, ":") {
+			return "invalid IPv6 domain literal"
+		}
+		return ""
+	}
+	if ip := net.ParseIP(literal); ip == nil || ip.To4() == nil || strings.Contains(literal, ":") {
+		return "invalid IPv4 domain literal"
+	}
+	return ""
+}
+
+func isAtext(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	case r >= utf8.RuneSelf:
+		// UTF-8 is allowed in dot-atoms by RFC 6531.
+		return r != utf8.RuneError
+	}
+	return strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", r)
+}
+
+func isLetterDigitHyphen(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+		return true
+	case r >= utf8.RuneSelf:
+		// Internationalized labels (U-labels) are allowed by RFC 6531.
+		return r != utf8.RuneError
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package mail_checker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Test ValidateAddress accepts valid addresses
+func TestValidateAddress_Valid(t *testing.T) {
+	valid := []string{
+		"simple@example.com",
+		"very.common@example.com",
+		"x@example.com",
+		"long.email-address-with-hyphens@and.subdomains.example.com",
+		"user.name+tag+sorting@example.com",
+		"name/surname@example.com",
+		"!#$%&'*+-/=?^_`{|}~@example.org",
+		`"john doe"@example.com`,
+		`"a@b"@example.com`,
+		`"very.(),:;<>[]\".VERY.\"very@\\ \"very\".unusual"@strange.example.com`,
+		"user@[192.168.2.1]",
+		"user@[IPv6:2001:db8::1]",
+		"user@xn--mnchen-3ya.de",
+		"user@münchen.de",
+		"nguyễn@ví-dụ.vn",
+		strings.Repeat("a", 64) + "@example.com",
+	}
+	for _, email := range valid {
+		if err := ValidateAddress(email); err != nil {
+			t.Errorf("%s: expected valid, got %v", email, err)
+		}
+	}
+}
+
+// Test ValidateAddress rejects invalid addresses
+func TestValidateAddress_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"plainaddress",
+		"a@@b.com",
+		`"@x"`,
+		"@example.com",
+		"user@",
+		"a@b",
+		".user@example.com",
+		"user.@example.com",
+		"us..er@example.com",
+		"us er@example.com",
+		`a"b@example.com`,
+		`"unterminated@example.com`,
+		`"bad"quote"@example.com`,
+		"user@-example.com",
+		"user@example-.com",
+		"user@exa_mple.com",
+		"user@example..com",
+		"user@example.com.",
+		"user@1.2.3.4",
+		"user@[300.1.1.1]",
+		"user@[IPv6:1.2.3.4]",
+		"user@[192.168.2.1",
+		strings.Repeat("a", 65) + "@example.com",
+		"user@" + strings.Repeat("a", 64) + ".com",
+		strings.Repeat("a", 64) + "@" + strings.Repeat(strings.Repeat("b", 60)+".", 4) + "com",
+		"user@exa\xffmple.com",
+	}
+	for _, email := range invalid {
+		err := ValidateAddress(email)
+		var addrErr *AddressError
+		if !errors.As(err, &addrErr) {
+			t.Errorf("%q: expected an AddressError, got %v", email, err)
+			continue
+		}
+		if addrErr.Address != email || addrErr.Reason == "" {
+			t.Errorf("%q: unexpected error %+v", email, addrErr)
+		}
+	}
+}
+
+// Test SplitAddress returns the local part and the domain
+func TestSplitAddress(t *testing.T) {
+	local, domain, err := SplitAddress(`"a@b"@example.com`)
+	if err != nil || local != `"a@b"` || domain != "example.com" {
+		t.Fatalf("unexpected split %q %q (%v)", local, domain, err)
+	}
+}
@@ -0,0 +1,43 @@
+package mail_checker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// CheckMany checks the emails with a bounded pool of workers sharing the
+// checker and returns the results in input order. A failed check is reported
+// in its own result and does not stop the batch. When ctx is done the
+// remaining emails are not checked: their results carry ctx.Err(), which is
+// also returned.
+func CheckMany(ctx context.Context, checker Checker, emails []string, opts BatchOptions) ([]CheckResult, error) {
+	results := make([]CheckResult, len(emails))
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = batchConcurrencyDefault
+	}
+	concurrency = min(concurrency, len(emails))
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(emails) {
+					return
+				}
+				if err := ctx.Err(); err != nil {
+					results[i] = newCheckResult("", emails[i]).finish(StatusIdCheckError, "", err)
+					continue
+				}
+				results[i] = checker.CheckDetail(ctx, emails[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return results, ctx.Err()
+}
@@ -0,0 +1,145 @@
+package mail_checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test CheckMany returns results in input order with bounded concurrency
+func TestCheckMany(t *testing.T) {
+	var running, peak atomic.Int32
+	checker := &stubChecker{kind: MailKindGoogle, checkFunc: func(ctx context.Context, email string) StatusId {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		if strings.HasPrefix(email, "live") {
+			return StatusIdLive
+		}
+		return StatusIdNotExists
+	}}
+
+	emails := make([]string, 50)
+	for i := range emails {
+		prefix := "gone"
+		if i%3 == 0 {
+			prefix = "live"
+		}
+		emails[i] = fmt.Sprintf("%s%d@gmail.com", prefix, i)
+	}
+
+	results, err := CheckMany(context.Background(), checker, emails, BatchOptions{Concurrency: 4})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != len(emails) {
+		t.Fatalf("expected %d results, got %d", len(emails), len(results))
+	}
+	for i, result := range results {
+		if result.Input != emails[i] {
+			t.Fatalf("result %d: expected %s, got %s", i, emails[i], result.Input)
+		}
+		want := StatusIdNotExists
+		if i%3 == 0 {
+			want = StatusIdLive
+		}
+		if result.Status.Id != want {
+			t.Fatalf("result %d: expected %v, got %v", i, want, result.Status.Id)
+		}
+	}
+	if peak.Load() > 4 {
+		t.Fatalf("expected at most 4 concurrent checks, got %d", peak.Load())
+	}
+}
+
+// Test CheckMany stops early when the context is cancelled
+func TestCheckMany_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	checker := &stubChecker{checkFunc: func(ctx context.Context, email string) StatusId {
+		if calls.Add(1) == 3 {
+			cancel()
+		}
+		return StatusIdLive
+	}}
+
+	emails := make([]string, 100)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("user%d@example.com", i)
+	}
+	results, err := CheckMany(ctx, checker, emails, BatchOptions{Concurrency: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected the batch to stop after 3 checks, got %d", calls.Load())
+	}
+	for i := 3; i < len(results); i++ {
+		if results[i].Status.Id != StatusIdCheckError || !errors.Is(results[i].Err, context.Canceled) {
+			t.Fatalf("result %d: expected a cancelled result, got %+v", i, results[i])
+		}
+		if results[i].Input != emails[i] {
+			t.Fatalf("result %d: expected %s, got %s", i, emails[i], results[i].Input)
+		}
+	}
+}
+
+// Test CheckMany on shared provider checkers keeps per-item errors
+func TestCheckMany_SharedProviders(t *testing.T) {
+	microsoft := &microsoftMail{client: newMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() == hotmailUrlSignup {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Set-Cookie": {"amsc=testCookie; path=/;"}},
+				Body:       io.NopCloser(strings.NewReader(`var ServerData={"apiCanary":"testCanary"};`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"isAvailable":false}`)),
+		}, nil
+	})}
+	yahoo := &yahooMail{client: newMockClient(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("mock error")
+	})}
+
+	for _, c := range []struct {
+		checker Checker
+		want    StatusId
+	}{{microsoft, StatusIdLive}, {yahoo, StatusIdCheckError}} {
+		emails := []string{"first@hotmail.com", "a@@b", "second@hotmail.com", "third@hotmail.com"}
+		results, err := CheckMany(context.Background(), c.checker, emails, BatchOptions{Concurrency: 3})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for i, result := range results {
+			want := c.want
+			if i == 1 {
+				want = StatusIdFormatInvalid
+			}
+			if result.Status.Id != want {
+				t.Fatalf("%T result %d: expected %v, got %v", c.checker, i, want, result.Status.Id)
+			}
+			if want == StatusIdCheckError && result.Err == nil {
+				t.Fatalf("%T result %d: expected the error to be kept", c.checker, i)
+			}
+		}
+	}
+
+	results, err := CheckMany(context.Background(), microsoft, nil, BatchOptions{})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("expected no results, got %v (%v)", results, err)
+	}
+}
@@ -0,0 +1,129 @@
+package mail_checker
+
+import (
+	"context"
+	"maps"
+	"time"
+)
+
+// Cache stores check results by key: the normalized address prefixed with
+// the kind of checker, so that checkers of different kinds can share a cache.
+// Get must not return an entry past its ExpiresAt. Implementations are used
+// by concurrent checks.
+type Cache interface {
+	Get(key string) (entry CacheEntry, ok bool)
+	Set(key string, entry CacheEntry)
+}
+
+// DefaultCachePolicy is the policy of NewCachedChecker when none is given:
+// definitive answers are kept for hours, failures are never cached. A checker
+// keeps the policy it was built with when it changes.
+var DefaultCachePolicy = CachePolicy{
+	TTL: map[StatusId]time.Duration{
+		StatusIdLive:        cacheTTLLiveDefault,
+		StatusIdDisable:     cacheTTLLiveDefault,
+		StatusIdCatchAll:    cacheTTLLiveDefault,
+		StatusIdNotExists:   cacheTTLNotExistDefault,
+		StatusIdVerPhone:    cacheTTLNotExistDefault,
+		StatusIdMailboxFull: cacheTTLShortDefault,
+	},
+}
+
+type cachedChecker struct {
+	checker Checker
+	kind    MailKind
+	cache   Cache
+	policy  CachePolicy
+	now     func() time.Time
+}
+
+// NewCachedChecker wraps the checker so that an address checked recently is
+// answered from the cache. Results are kept for the TTL of their status in
+// the policy; a status without a TTL is not cached. A policy without TTLs
+// takes DefaultCachePolicy.
+func NewCachedChecker(checker Checker, cache Cache, policy CachePolicy) Checker {
+	if policy.TTL == nil {
+		policy = DefaultCachePolicy
+	}
+	return &cachedChecker{
+		checker: checker,
+		kind:    checkerKind(checker),
+		cache:   cache,
+		policy:  CachePolicy{TTL: maps.Clone(policy.TTL)},
+		now:     time.Now,
+	}
+}
+
+// WithCache makes NewWithOptions wrap the checker with NewCachedChecker, to
+// answer the addresses checked recently from the cache, following the
+// policy.
+func WithCache(cache Cache, policy CachePolicy) Option {
+	return func(o *options) {
+		o.cache = &cacheOptions{cache: cache, policy: policy}
+	}
+}
+
+func (c *cachedChecker) Check(email string) (status Status) {
+	return c.CheckContext(context.Background(), email)
+}
+
+func (c *cachedChecker) CheckContext(ctx context.Context, email string) (status Status) {
+	return c.CheckDetail(ctx, email).Status
+}
+
+func (c *cachedChecker) CheckDetail(ctx context.Context, email string) (result CheckResult) {
+	address, err := NormalizeAddress(email)
+	if err != nil {
+		return c.checker.CheckDetail(ctx, email)
+	}
+	kind := c.kind
+	if kind == MailKindAuto {
+		// The router answers with the provider's checker: share its entries.
+		kind, _ = DetectMailKind(address)
+	}
+	key := string(kind) + cacheKeySeparator + address
+
+	if entry, ok := c.cache.Get(key); ok {
+		result = newCheckResult(entry.Kind, email)
+		result.Email = entry.Email
+		result.Attempts = 0
+		result.Cached = true
+		return result.finish(entry.Status.Id, entry.Reason, nil)
+	}
+
+	result = c.checker.CheckDetail(ctx, email)
+	if ttl := c.policy.TTL[result.Status.Id]; ttl > 0 && result.Err == nil {
+		checkedAt := c.now()
+		c.cache.Set(key, CacheEntry{
+			Email:     address,
+			Kind:      result.Kind,
+			Status:    result.Status,
+			Reason:    result.Reason,
+			CheckedAt: checkedAt,
+			ExpiresAt: checkedAt.Add(ttl),
+		})
+	}
+	return result
+}
+
+// checkerKind returns the kind of a checker of this package, looking through
+// the decorators, or "" for other checkers.
+func checkerKind(checker Checker) MailKind {
+	switch c := checker.(type) {
+	case *microsoftMail:
+		return MailKindMicrosoft
+	case *yahooMail:
+		return MailKindYahoo
+	case *googleMail:
+		return MailKindGoogle
+	case *smtpMail:
+		return MailKindSmtp
+	case *routerMail:
+		return MailKindAuto
+	case *retryChecker:
+		return checkerKind(c.checker)
+	case *cachedChecker:
+		return c.kind
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package mail_checker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test the cached checker answers repeated addresses from the cache
+func TestCachedChecker(t *testing.T) {
+	var calls atomic.Int32
+	stub := &stubChecker{kind: MailKindMicrosoft, checkFunc: func(ctx context.Context, email string) StatusId {
+		calls.Add(1)
+		return StatusIdLive
+	}}
+	checker := NewCachedChecker(stub, NewLRUCache(10), CachePolicy{})
+
+	first := checker.CheckDetail(context.Background(), "Test@Hotmail.com")
+	if first.Cached || first.Status.Id != StatusIdLive {
+		t.Fatalf("expected a live uncached result, got %+v", first)
+	}
+	second := checker.CheckDetail(context.Background(), " test@hotmail.com ")
+	if !second.Cached || second.Status.Id != StatusIdLive || second.Kind != MailKindMicrosoft {
+		t.Fatalf("expected a live cached result, got %+v", second)
+	}
+	if second.Email != "test@hotmail.com" || second.Input != " test@hotmail.com " || second.Attempts != 0 {
+		t.Fatalf("unexpected cached result fields: %+v", second)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 upstream check, got %d", calls.Load())
+	}
+}
+
+// Test only statuses with a TTL are cached, and only until it runs out
+func TestCachedCheckerPolicy(t *testing.T) {
+	statuses := map[string]StatusId{
+		"live@hotmail.com":  StatusIdLive,
+		"error@hotmail.com": StatusIdCheckError,
+	}
+	var calls atomic.Int32
+	stub := &stubChecker{kind: MailKindMicrosoft, checkFunc: func(ctx context.Context, email string) StatusId {
+		calls.Add(1)
+		return statuses[email]
+	}}
+	cache := NewLRUCache(10)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	checker := NewCachedChecker(stub, cache, CachePolicy{TTL: map[StatusId]time.Duration{StatusIdLive: time.Hour}})
+	checker.(*cachedChecker).now = cache.now
+
+	for i := 0; i < 3; i++ {
+		checker.Check("live@hotmail.com")
+		checker.Check("error@hotmail.com")
+	}
+	if calls.Load() != 4 {
+		t.Fatalf("expected 4 upstream checks, got %d", calls.Load())
+	}
+
+	now = now.Add(time.Hour)
+	if result := checker.CheckDetail(context.Background(), "live@hotmail.com"); result.Cached {
+		t.Fatalf("expected the expired entry to be checked again")
+	}
+	if calls.Load() != 5 {
+		t.Fatalf("expected 5 upstream checks, got %d", calls.Load())
+	}
+}
+
+// Test invalid addresses go straight to the wrapped checker
+func TestCachedCheckerInvalidAddress(t *testing.T) {
+	checker := NewCachedChecker(newYahooMail(nil), NewLRUCache(10), CachePolicy{})
+	if status := checker.Check("not an address"); status.Id != StatusIdFormatInvalid {
+		t.Fatalf("expected StatusIdFormatInvalid, got %v", status.Id)
+	}
+}
+
+// Test a cache shared by checkers of different kinds keeps their answers
+// apart, while the router shares the entries of the provider's checker
+func TestCachedCheckerSharedCache(t *testing.T) {
+	standIn := newSmtpStandIn(t, "220 stand-in ESMTP", map[string]string{"live": "250 2.1.5 OK"}, "550 5.1.1 user unknown")
+	cache := NewLRUCache(10)
+	smtp := NewCachedChecker(standIn.checker(), cache, CachePolicy{})
+	if result := smtp.CheckDetail(context.Background(), "live@hotmail.com"); result.Status.Id != StatusIdLive || result.Cached {
+		t.Fatalf("expected a live uncached SMTP result, got %+v", result)
+	}
+
+	var calls atomic.Int32
+	client := &http.Client{Transport: &mockTransport{RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return nil, errors.New("upstream unreachable")
+	}}}
+	microsoft := NewCachedChecker(&microsoftMail{client: client}, cache, CachePolicy{})
+	if result := microsoft.CheckDetail(context.Background(), "live@hotmail.com"); result.Cached || calls.Load() == 0 {
+		t.Fatalf("expected the Microsoft checker not to get the SMTP answer, got %+v", result)
+	}
+
+	cache.Set("microsoft:live@hotmail.com", CacheEntry{Email: "live@hotmail.com", Kind: MailKindMicrosoft, Status: getStatusById(StatusIdNotExists), ExpiresAt: time.Now().Add(time.Hour)})
+	router := NewCachedChecker(&routerMail{checkers: map[MailKind]Checker{MailKindMicrosoft: &microsoftMail{client: client}}}, cache, CachePolicy{})
+	if result := router.CheckDetail(context.Background(), "live@hotmail.com"); !result.Cached || result.Status.Id != StatusIdNotExists {
+		t.Fatalf("expected the router to share the Microsoft entry, got %+v", result)
+	}
+}
+
+// Test WithCache wraps the built checker and keeps a copy of the policy
+func TestWithCache(t *testing.T) {
+	checker, err := NewWithOptions(MailKindMicrosoft, WithCache(NewLRUCache(10), CachePolicy{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cached, ok := checker.(*cachedChecker)
+	if !ok || cached.kind != MailKindMicrosoft {
+		t.Fatalf("expected a cached microsoft checker, got %T", checker)
+	}
+
+	ttl := DefaultCachePolicy.TTL[StatusIdLive]
+	DefaultCachePolicy.TTL[StatusIdLive] = time.Second
+	defer func() { DefaultCachePolicy.TTL[StatusIdLive] = ttl }()
+	if cached.policy.TTL[StatusIdLive] != ttl {
+		t.Errorf("expected the checker to keep its policy, got %v", cached.policy.TTL[StatusIdLive])
+	}
+
+	if _, err = NewWithOptions(MailKindMicrosoft, WithCache(nil, CachePolicy{})); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
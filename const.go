@@ -9,6 +9,9 @@ const (
 	StatusIdVerPhone      StatusId = 4
 	StatusIdCheckError    StatusId = 5
 	StatusIdFormatInvalid StatusId = 6
+	StatusIdUnsupported   StatusId = 7
+	StatusIdMailboxFull   StatusId = 8
+	StatusIdCatchAll      StatusId = 9
 
 	StatusNameLive          StatusName = "Live"
 	StatusNameNotExists     StatusName = "Not exists"
@@ -16,16 +19,24 @@ const (
 	StatusNameVerPhone      StatusName = "Ver phone"
 	StatusNameCheckError    StatusName = "Check error"
 	StatusNameFormatInvalid StatusName = "Format Invalid"
+	StatusNameUnsupported   StatusName = "Unsupported provider"
+	StatusNameMailboxFull   StatusName = "Mailbox full"
+	StatusNameCatchAll      StatusName = "Catch all"
 )
 
 const (
 	MailKindMicrosoft        MailKind = "microsoft"
 	MailKindGoogle           MailKind = "google"
 	MailKindYahoo            MailKind = "yahoo"
+	MailKindAuto             MailKind = "auto"
+	MailKindSmtp             MailKind = "smtp"
 	dialProtocol                      = "tcp"
 	hotmailUrlSignup                  = "https://signup.live.com/signup"
 	hotmailUrlCheckAvailable          = "https://signup.live.com/API/CheckAvailableSigninNames"
 
+	hotmailReasonAvailable    = "isAvailable=true"
+	hotmailReasonNotAvailable = "isAvailable=false"
+
 	yahooCreateAccountUrl                       = "https://login.yahoo.com/account/create"
 	yahooCheckerUrlApi                          = "https://login.yahoo.com/account/module/create?validateField=userId"
 	yahooKeyCheckExists                         = "userId"
@@ -35,5 +46,99 @@ const (
 	yahooTextDetectErrorLengthTooShort          = "LENGTH_TOO_SHORT"
 	yahooTextDetectErrorSomeSpecialCharNotAllow = "SOME_SPECIAL_CHARACTERS_NOT_ALLOWED"
 
+	googleUrlSignup             = "https://accounts.google.com/signup/v2/createusername?flowName=GlifWebSignIn&flowEntry=SignUp"
+	googleUrlCheckAvailable     = "https://accounts.google.com/_/signup/usernameavailability?hl=en"
+	googleCookieSession         = "__Host-GAPS"
+	googleResponsePrefix        = ")]}'"
+	googleKeyUsernameAvailable  = "gf.uar"
+	googleCodeUsernameAvailable = 1
+	googleCodeUsernameTaken     = 2
+
+	smtpPortDefault       = "25"
+	smtpHeloNameDefault   = "localhost"
+	smtpTimeoutDefault    = 15 * time.Second
+	smtpProbeLocalPartLen = 16
+
+	addressMaxLength       = 254
+	addressLocalMaxLength  = 64
+	addressDomainMaxLength = 253
+	addressLabelMaxLength  = 63
+
+	yahooUsernameMinLength  = 4
+	yahooUsernameMaxLength  = 32
+	googleUsernameMinLength = 6
+	googleUsernameMaxLength = 30
+
+	batchConcurrencyDefault = 10
+
+	StreamFormatNDJSON StreamFormat = "ndjson"
+	StreamFormatCSV    StreamFormat = "csv"
+	streamWindowFactor              = 2
+	streamLineMaxBytes              = 4 << 10
+	cacheKeySeparator               = ":"
+
+	AddrFamilyAny        AddrFamily = ""
+	AddrFamilyIPv4       AddrFamily = "ipv4"
+	AddrFamilyIPv6       AddrFamily = "ipv6"
+	AddrFamilyPreferIPv4 AddrFamily = "prefer-ipv4"
+	AddrFamilyPreferIPv6 AddrFamily = "prefer-ipv6"
+	dnsPortDefault                  = "53"
+
+	CheckPhaseBootstrap CheckPhase = "bootstrap"
+	CheckPhaseProbe     CheckPhase = "probe"
+	CheckPhaseCheck     CheckPhase = "check"
+
+	rateLimitRateDefault  = 5
+	rateLimitBurstDefault = 5
+
+	retryMaxAttemptsDefault = 3
+	retryBaseDelayDefault   = 500 * time.Millisecond
+	retryMaxDelayDefault    = 5 * time.Second
+
+	sessionTTLDefault      = 5 * time.Minute
+	sessionMaxUsesDefault  = 100
+	sessionRejectedRetries = 1
+
 	httpClientTimeoutDefault = 5 * time.Second
+
+	proxySchemaHttp      = "http"
+	proxySchemaHttps     = "https"
+	proxySchemaSocks5    = "socks5"
+	proxySchemaSocks5h   = "socks5h"
+	socksAuthFailedText  = "username/password authentication failed"
+	proxySchemeSeparator = "://"
+	proxyTestUrlDefault  = "https://signup.live.com/"
+
+	cacheTTLLiveDefault     = 24 * time.Hour
+	cacheTTLNotExistDefault = 6 * time.Hour
+	cacheTTLShortDefault    = time.Hour
+
+	fileCacheCompactMinRecords = 1000
+	fileCacheCompactFactor     = 2
+	fileCacheFileMode          = 0o600
+
+	logKeyProvider  = "provider"
+	logKeyPhase     = "phase"
+	logKeyEmailHash = "email_hash"
+	logKeyError     = "error"
+	logEmailHashLen = 8
+	logrusBadKey    = "!BADKEY"
+
+	logPhaseValidate  = "validate"
+	logPhaseBootstrap = "bootstrap"
+	logPhaseProbe     = "probe"
+	logPhaseRoute     = "route"
+	logPhaseLookup    = "lookup"
+	logPhaseConnect   = "connect"
+	logPhaseConverse  = "conversation"
+	logPhaseCache     = "cache"
+
+	transportMaxIdleConnsDefault        = 100
+	transportMaxIdleConnsPerHostDefault = 16
+	transportIdleConnTimeoutDefault     = 90 * time.Second
+	transportTLSHandshakeTimeoutDefault = 10 * time.Second
+	transportDialTimeoutDefault         = 30 * time.Second
+	transportKeepAliveDefault           = 30 * time.Second
+	dialDNSTimeoutDefault               = 5 * time.Second
+	transportDrainMaxBytes              = 64 << 10
 )
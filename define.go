@@ -1,15 +1,26 @@
 package mail_checker
 
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"time"
+)
+
 type (
-	StatusId   int
-	StatusName string
-	MailKind   string
+	StatusId     int
+	StatusName   string
+	MailKind     string
+	StreamFormat string
+	AddrFamily   string
+	CheckPhase   string
 
 	Proxy struct {
 		Host     string
 		Schema   string
 		User     string
 		Password string
+		NoProxy  string
 	}
 
 	Status struct {
@@ -17,6 +28,149 @@ type (
 		Name StatusName `json:"name"`
 	}
 
+	CheckResult struct {
+		Input      string        `json:"input"`
+		Email      string        `json:"email"`
+		Kind       MailKind      `json:"kind"`
+		Status     Status        `json:"status"`
+		Err        error         `json:"-"`
+		Reason     string        `json:"reason,omitempty"`
+		Attempts   int           `json:"attempts"`
+		StartedAt  time.Time     `json:"started_at"`
+		FinishedAt time.Time     `json:"finished_at"`
+		Latency    time.Duration `json:"latency"`
+		Cached     bool          `json:"cached,omitempty"`
+		// TimeoutPhase is the phase whose time limit expired, if any.
+		TimeoutPhase CheckPhase `json:"timeout_phase,omitempty"`
+	}
+
+	BatchOptions struct {
+		Concurrency int
+	}
+
+	StreamOptions struct {
+		Concurrency int
+		Format      StreamFormat
+		Ordered     bool
+	}
+
+	streamRecord struct {
+		Input     string     `json:"input"`
+		Email     string     `json:"email"`
+		Kind      MailKind   `json:"kind"`
+		StatusId  StatusId   `json:"status_id"`
+		Status    StatusName `json:"status"`
+		Reason    string     `json:"reason,omitempty"`
+		Error     string     `json:"error,omitempty"`
+		Attempts  int        `json:"attempts"`
+		LatencyMs int64      `json:"latency_ms"`
+	}
+
+	RetryPolicy struct {
+		MaxAttempts int
+		BaseDelay   time.Duration
+		MaxDelay    time.Duration
+	}
+
+	SessionPolicy struct {
+		TTL     time.Duration
+		MaxUses int
+	}
+
+	CachePolicy struct {
+		TTL map[StatusId]time.Duration
+	}
+
+	CacheEntry struct {
+		Email     string
+		Kind      MailKind
+		Status    Status
+		Reason    string
+		CheckedAt time.Time
+		ExpiresAt time.Time
+	}
+
+	fileCacheRecord struct {
+		Key       string    `json:"key"`
+		Email     string    `json:"email"`
+		Kind      MailKind  `json:"kind"`
+		StatusId  StatusId  `json:"status_id"`
+		Reason    string    `json:"reason,omitempty"`
+		CheckedAt time.Time `json:"checked_at"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	Endpoints struct {
+		Bootstrap string
+		Probe     string
+	}
+
+	Option func(o *options)
+
+	options struct {
+		client       *http.Client
+		timeout      time.Duration
+		proxy        Proxy
+		proxyFromEnv bool
+		logger       Logger
+		userAgent    string
+		endpoints    *Endpoints
+		tls          tlsOptions
+		dial         dialOptions
+		timeouts     phaseTimeouts
+		rateLimit    *rateLimit
+		limiter      *RateLimiter
+		session      *SessionPolicy
+		transport    *TransportPolicy
+		cache        *cacheOptions
+	}
+
+	cacheOptions struct {
+		cache  Cache
+		policy CachePolicy
+	}
+
+	rateLimit struct {
+		rate  float64
+		burst int
+	}
+
+	phaseTimeouts struct {
+		bootstrap time.Duration
+		probe     time.Duration
+		check     time.Duration
+	}
+
+	dialOptions struct {
+		localAddr string
+		family    AddrFamily
+		timeout   time.Duration
+		keepAlive time.Duration
+		dnsServer string
+	}
+
+	tlsOptions struct {
+		rootCAs      *x509.CertPool
+		rootCAFile   string
+		minVersion   uint16
+		certificates []tls.Certificate
+		certFile     string
+		keyFile      string
+	}
+
+	TransportPolicy struct {
+		MaxIdleConns        int
+		MaxIdleConnsPerHost int
+		MaxConnsPerHost     int
+		IdleConnTimeout     time.Duration
+		DisableHTTP2        bool
+	}
+
+	microsoftSession struct {
+		Amsc   string
+		Canary string
+	}
+
 	microsoftMailResCanary struct {
 		ApiCanary string `json:"apiCanary"`
 	}
@@ -24,6 +178,11 @@ type (
 		IsAvailable bool `json:"isAvailable"`
 	}
 
+	googleSession struct {
+		Cookie string
+		Xsrf   string
+	}
+
 	yahooBodyChecker struct {
 		SpecId        string `url:"specId"`
 		CacheStored   string `url:"cacheStored"`
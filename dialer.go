@@ -0,0 +1,220 @@
+package mail_checker
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WithLocalAddr makes the checker connect from the IP address, for hosts with
+// several interfaces. It applies to every kind, SMTP included.
+func WithLocalAddr(ip string) Option {
+	return func(o *options) {
+		o.dial.localAddr = ip
+	}
+}
+
+// WithAddrFamily restricts the connections to IPv4 or IPv6, or tries the
+// addresses of one family before those of the other. The default lets the
+// system choose, trying the other family when the first one is slow.
+func WithAddrFamily(family AddrFamily) Option {
+	return func(o *options) {
+		o.dial.family = family
+	}
+}
+
+// WithConnectTimeout sets the time limit of establishing a connection, for
+// each address tried. The default is 30 seconds, or the SMTP timeout for the
+// SMTP kind.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.dial.timeout = timeout
+	}
+}
+
+// WithKeepAlive sets the interval of the TCP keep-alive probes. The default
+// is 30 seconds; a negative interval disables them.
+func WithKeepAlive(interval time.Duration) Option {
+	return func(o *options) {
+		o.dial.keepAlive = interval
+	}
+}
+
+// WithDNSServer makes the checker resolve the host names, MX records
+// included, with the DNS server at the IP address, port 53 unless given.
+// SOCKS5 proxies with the socks5h schema keep resolving the names themselves.
+func WithDNSServer(address string) Option {
+	return func(o *options) {
+		o.dial.dnsServer = address
+	}
+}
+
+func (o dialOptions) validate() error {
+	switch o.family {
+	case AddrFamilyAny, AddrFamilyIPv4, AddrFamilyIPv6, AddrFamilyPreferIPv4, AddrFamilyPreferIPv6:
+	default:
+		return fmt.Errorf("%w: unknown address family %q", ErrInvalidOption, o.family)
+	}
+	if o.localAddr != "" {
+		ip := net.ParseIP(o.localAddr)
+		if ip == nil {
+			return fmt.Errorf("%w: local address %q is not an IP address", ErrInvalidOption, o.localAddr)
+		}
+		if !o.allows(ip) {
+			return fmt.Errorf("%w: local address %s is not an %s address", ErrInvalidOption, o.localAddr, o.family)
+		}
+	}
+	if o.timeout < 0 {
+		return fmt.Errorf("%w: negative connect timeout %v", ErrInvalidOption, o.timeout)
+	}
+	if o.dnsServer != "" {
+		host, _, err := net.SplitHostPort(o.dnsServerAddr())
+		if err != nil || net.ParseIP(host) == nil {
+			return fmt.Errorf("%w: DNS server %q is not an IP address", ErrInvalidOption, o.dnsServer)
+		}
+	}
+	return nil
+}
+
+// allows reports whether the address family lets the checker use the IP.
+func (o dialOptions) allows(ip net.IP) bool {
+	switch o.family {
+	case AddrFamilyIPv4:
+		return ip.To4() != nil
+	case AddrFamilyIPv6:
+		return ip.To4() == nil
+	}
+	return true
+}
+
+func (o dialOptions) dnsServerAddr() string {
+	if _, _, err := net.SplitHostPort(o.dnsServer); err == nil {
+		return o.dnsServer
+	}
+	return net.JoinHostPort(strings.Trim(o.dnsServer, "[]"), dnsPortDefault)
+}
+
+// dialer connects to the upstreams following the dial options. The options
+// must have been validated.
+type dialer struct {
+	net.Dialer
+	options dialOptions
+}
+
+func newDialer(options dialOptions) *dialer {
+	d := &dialer{
+		Dialer: net.Dialer{
+			Timeout:   transportDialTimeoutDefault,
+			KeepAlive: transportKeepAliveDefault,
+		},
+		options: options,
+	}
+	if options.timeout > 0 {
+		d.Timeout = options.timeout
+	}
+	if options.keepAlive != 0 {
+		d.KeepAlive = options.keepAlive
+	}
+	if options.localAddr != "" {
+		d.LocalAddr = &net.TCPAddr{IP: net.ParseIP(options.localAddr)}
+	}
+	if options.dnsServer != "" {
+		server := options.dnsServerAddr()
+		d.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				dns := net.Dialer{Timeout: dialDNSTimeoutDefault}
+				if ip := net.ParseIP(options.localAddr); ip != nil {
+					if strings.HasPrefix(network, "udp") {
+						dns.LocalAddr = &net.UDPAddr{IP: ip}
+					} else {
+						dns.LocalAddr = &net.TCPAddr{IP: ip}
+					}
+				}
+				return dns.DialContext(ctx, network, server)
+			},
+		}
+	}
+	return d
+}
+
+func (d *dialer) resolver() *net.Resolver {
+	if d.Resolver == nil {
+		return net.DefaultResolver
+	}
+	return d.Resolver
+}
+
+// Dial is DialContext without a context, for the SOCKS5 dialer.
+func (d *dialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+// DialContext connects to the address. With an address family set, it tries
+// the IP addresses of the host one after the other, in the family's order.
+func (d *dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if d.options.family == AddrFamilyAny {
+		return d.Dialer.DialContext(ctx, network, address)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ip := range ips {
+		var conn net.Conn
+		if conn, err = d.Dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port)); err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
+
+// lookup returns the IP addresses of the host the address family allows, in
+// the order to try them.
+func (d *dialer) lookup(ctx context.Context, host string) ([]net.IP, error) {
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := d.resolver().LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	ips = slices.DeleteFunc(ips, func(ip net.IP) bool {
+		return !d.options.allows(ip)
+	})
+	if len(ips) == 0 {
+		return nil, &net.DNSError{Err: "no " + string(d.options.family) + " address", Name: host, IsNotFound: true}
+	}
+	if prefer := d.options.family; prefer == AddrFamilyPreferIPv4 || prefer == AddrFamilyPreferIPv6 {
+		slices.SortStableFunc(ips, func(a, b net.IP) int {
+			return cmp.Compare(d.rank(a), d.rank(b))
+		})
+	}
+	return ips, nil
+}
+
+// rank is 0 for the IP addresses of the preferred family, 1 for the others.
+func (d *dialer) rank(ip net.IP) int {
+	if (ip.To4() != nil) == (d.options.family == AddrFamilyPreferIPv4) {
+		return 0
+	}
+	return 1
+}
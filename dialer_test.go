@@ -0,0 +1,204 @@
+package mail_checker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsStandIn is a DNS server on a loopback UDP port answering every name it
+// knows with 127.0.0.1 and ::1, and the MX records configured for it.
+type dnsStandIn struct {
+	conn    net.PacketConn
+	names   map[string][]string
+	queries atomic.Int32
+}
+
+func newDnsStandIn(t *testing.T, names map[string][]string) *dnsStandIn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &dnsStandIn{conn: conn, names: names}
+	go s.serve()
+	t.Cleanup(func() { conn.Close() })
+	return s
+}
+
+func (s *dnsStandIn) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var query dnsmessage.Message
+		if query.Unpack(buf[:n]) != nil || len(query.Questions) != 1 {
+			continue
+		}
+		s.queries.Add(1)
+		answer := s.answer(query)
+		if packed, err := answer.Pack(); err == nil {
+			s.conn.WriteTo(packed, addr)
+		}
+	}
+}
+
+func (s *dnsStandIn) answer(query dnsmessage.Message) dnsmessage.Message {
+	question := query.Questions[0]
+	answer := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true},
+		Questions: query.Questions,
+	}
+	mxs, ok := s.names[strings.TrimSuffix(question.Name.String(), ".")]
+	if !ok {
+		answer.RCode = dnsmessage.RCodeNameError
+		return answer
+	}
+
+	header := dnsmessage.ResourceHeader{Name: question.Name, Type: question.Type, Class: question.Class, TTL: 60}
+	switch question.Type {
+	case dnsmessage.TypeA:
+		answer.Answers = append(answer.Answers, dnsmessage.Resource{Header: header, Body: &dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}}})
+	case dnsmessage.TypeAAAA:
+		answer.Answers = append(answer.Answers, dnsmessage.Resource{Header: header, Body: &dnsmessage.AAAAResource{AAAA: [16]byte{15: 1}}})
+	case dnsmessage.TypeMX:
+		for _, mx := range mxs {
+			answer.Answers = append(answer.Answers, dnsmessage.Resource{Header: header, Body: &dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName(mx)}})
+		}
+	}
+	return answer
+}
+
+// Test the address family filters and orders the resolved addresses
+func TestDialerLookup(t *testing.T) {
+	dns := newDnsStandIn(t, map[string][]string{"mail.test": nil})
+	server := dns.conn.LocalAddr().String()
+
+	cases := map[AddrFamily][]string{
+		AddrFamilyIPv4:       {"127.0.0.1"},
+		AddrFamilyIPv6:       {"::1"},
+		AddrFamilyPreferIPv4: {"127.0.0.1", "::1"},
+		AddrFamilyPreferIPv6: {"::1", "127.0.0.1"},
+	}
+	for family, want := range cases {
+		ips, err := newDialer(dialOptions{family: family, dnsServer: server}).lookup(context.Background(), "mail.test")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", family, err)
+		}
+		var got []string
+		for _, ip := range ips {
+			got = append(got, ip.String())
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: expected %v, got %v", family, want, got)
+		}
+	}
+
+	_, err := newDialer(dialOptions{family: AddrFamilyIPv6}).lookup(context.Background(), "127.0.0.1")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("expected no IPv6 address for an IPv4 literal, got %v", err)
+	}
+	if dns.queries.Load() == 0 {
+		t.Errorf("expected the names to be resolved by the DNS server")
+	}
+}
+
+// Test the dial options reach the provider requests
+func TestDialerOptionsHTTP(t *testing.T) {
+	dns := newDnsStandIn(t, map[string][]string{"signup.test": nil})
+	var remoteAddr atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddr.Store(r.RemoteAddr)
+		switch r.URL.Path {
+		case "/signup":
+			w.Header().Set("Set-Cookie", "amsc=testCookie; path=/;")
+			io.WriteString(w, `var ServerData={"apiCanary":"testCanary"};`)
+		case "/check":
+			io.WriteString(w, `{"isAvailable":false}`)
+		}
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	base := "http://signup.test:" + port
+
+	check := func(family AddrFamily, opts ...Option) CheckResult {
+		checker, err := NewWithOptions(MailKindMicrosoft, append(opts,
+			WithEndpoints(Endpoints{Bootstrap: base + "/signup", Probe: base + "/check"}),
+			WithDNSServer(dns.conn.LocalAddr().String()),
+			WithAddrFamily(family),
+			WithConnectTimeout(time.Second),
+			WithKeepAlive(-1),
+			WithRateLimit(0, 0),
+		)...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", family, err)
+		}
+		return checker.CheckDetail(context.Background(), "test@hotmail.com")
+	}
+
+	if result := check(AddrFamilyIPv4, WithLocalAddr("127.0.0.1")); result.Status.Id != StatusIdLive {
+		t.Fatalf("expected StatusIdLive over IPv4, got %v (%v)", result.Status.Id, result.Err)
+	}
+	if host, _, _ := net.SplitHostPort(remoteAddr.Load().(string)); host != "127.0.0.1" {
+		t.Errorf("expected the request from 127.0.0.1, got %s", host)
+	}
+	// The server only listens on IPv4.
+	if result := check(AddrFamilyIPv6); result.Status.Id != StatusIdCheckError {
+		t.Errorf("expected IPv6 only to fail, got %v", result.Status.Id)
+	}
+}
+
+// Test the SMTP checker resolves the MX records with the DNS server and falls
+// back to the other address family
+func TestDialerOptionsSmtp(t *testing.T) {
+	standIn := newSmtpStandIn(t, "220 stand-in ESMTP", map[string]string{"live": "250 2.1.5 OK"}, "550 5.1.1 user unknown")
+	dns := newDnsStandIn(t, map[string][]string{"mail.test": {"mx.mail.test."}, "mx.mail.test": nil})
+
+	checker, err := NewWithOptions(MailKindSmtp,
+		WithDNSServer(dns.conn.LocalAddr().String()),
+		WithAddrFamily(AddrFamilyPreferIPv6),
+		WithConnectTimeout(time.Second),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	smtp := checker.(*smtpMail)
+	_, smtp.port, _ = net.SplitHostPort(standIn.listener.Addr().String())
+
+	// ::1 is tried first and refused, as the stand-in only listens on IPv4.
+	if result := smtp.CheckDetail(context.Background(), "live@mail.test"); result.Status.Id != StatusIdLive {
+		t.Errorf("expected StatusIdLive, got %v (%v)", result.Status.Id, result.Err)
+	}
+}
+
+// Test invalid dial options are rejected when the checker is built
+func TestDialerOptionsErrors(t *testing.T) {
+	invalid := map[string][]Option{
+		"unknown family":          {WithAddrFamily("ipv5")},
+		"local address host name": {WithLocalAddr("localhost")},
+		"local address family":    {WithLocalAddr("::1"), WithAddrFamily(AddrFamilyIPv4)},
+		"negative timeout":        {WithConnectTimeout(-time.Second)},
+		"DNS server host name":    {WithDNSServer("dns.google")},
+	}
+	for name, opts := range invalid {
+		for _, kind := range []MailKind{MailKindAuto, MailKindSmtp} {
+			if _, err := NewWithOptions(kind, opts...); !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("%s, %s: expected ErrInvalidOption, got %v", name, kind, err)
+			}
+		}
+	}
+	if _, err := NewWithOptions(MailKindAuto, WithDNSServer("8.8.8.8"), WithDNSServer("[2001:4860:4860::8888]:53")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -1,8 +1,165 @@
 package mail_checker
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// The failure modes of a check. The errors returned by the checkers match
+// one of them with errors.Is when their cause is known.
+var (
+	ErrSessionTokenMissing   = errors.New("session token missing from the upstream page")
+	ErrUpstreamSchemaChanged = errors.New("upstream response schema changed")
+	ErrRateLimited           = errors.New("rate limited by upstream")
+	ErrProxyAuth             = errors.New("proxy authentication failed")
+	ErrProxyConnect          = errors.New("proxy connection failed")
+	ErrProxyTLS              = errors.New("proxy TLS handshake failed")
+	ErrTimeout               = errors.New("check timed out")
+	ErrInvalidFormat         = errors.New("invalid email format")
+)
 
 var (
-	ErrMicrosoftGetAmscCookieError   = errors.New("get amsc cookie fail")
-	ErrMicrosoftGetCanaryCookieError = errors.New("get canary cookie fail")
+	ErrUnsupportedProvider = errors.New("no checker supports the email domain")
+	ErrUnknownStreamFormat = errors.New("unknown stream format")
+	ErrFileCacheClosed     = errors.New("file cache is closed")
+	ErrInvalidMailKind     = errors.New("invalid mail kind")
+	ErrInvalidOption       = errors.New("invalid option")
+	ErrInvalidProxy        = errors.New("invalid proxy")
+
+	ErrMicrosoftGetAmscCookieError   = tagError(ErrSessionTokenMissing, errors.New("get amsc cookie fail"))
+	ErrMicrosoftGetCanaryCookieError = tagError(ErrSessionTokenMissing, errors.New("get canary cookie fail"))
+	ErrMicrosoftIsAvailableMissing   = tagError(ErrUpstreamSchemaChanged, errors.New("isAvailable field missing in response"))
+
+	ErrGoogleGetSessionCookieError = tagError(ErrSessionTokenMissing, errors.New("get google session cookie fail"))
+	ErrGoogleGetXsrfTokenError     = tagError(ErrSessionTokenMissing, errors.New("get google xsrf token fail"))
+	ErrGoogleUnexpectedResponse    = tagError(ErrUpstreamSchemaChanged, errors.New("unexpected username availability response"))
+
+	ErrYahooGetCookieError     = tagError(ErrSessionTokenMissing, errors.New("could not detect cookies"))
+	ErrYahooGetSessionValue    = tagError(ErrSessionTokenMissing, errors.New("session value missing"))
+	ErrYahooErrorsFieldMissing = tagError(ErrUpstreamSchemaChanged, errors.New("errors field missing in response"))
+	ErrYahooUserIdRejected     = tagError(ErrInvalidFormat, errors.New("user id rejected by upstream"))
+
+	ErrSmtpNoMailHost = errors.New("no mail host accepted the connection")
 )
+
+// AddressError reports an email address that is not syntactically valid.
+type AddressError struct {
+	Address string
+	Reason  string
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("invalid email address %q: %s", e.Address, e.Reason)
+}
+
+func (e *AddressError) Is(target error) bool {
+	return target == ErrInvalidFormat
+}
+
+// HttpStatusError is an upstream response with a status code that carries no
+// verdict. Throttling (429) and server errors (5xx) are temporary.
+type HttpStatusError struct {
+	StatusCode int
+	Url        string
+}
+
+func (e *HttpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s", e.StatusCode, e.Url)
+}
+
+func (e *HttpStatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// RateLimitError is an upstream throttling the checks. RetryAfter is the
+// delay the upstream asked for, zero when it did not say.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s, retry after %v", e.Err.Error(), e.RetryAfter)
+	}
+	return e.Err.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+func (e *RateLimitError) Temporary() bool {
+	return true
+}
+
+// PhaseTimeoutError is a check that ran out of time in a phase. Timeout is
+// the limit of the phase, zero when the request or the caller's context
+// timed out instead.
+type PhaseTimeoutError struct {
+	Phase   CheckPhase
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *PhaseTimeoutError) Error() string {
+	message := fmt.Sprintf("%s timed out", e.Phase)
+	if e.Timeout > 0 {
+		message += fmt.Sprintf(" after %v", e.Timeout)
+	}
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *PhaseTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PhaseTimeoutError) Is(target error) bool {
+	return target == ErrTimeout || target == context.DeadlineExceeded
+}
+
+// taggedError adds one of the failure modes to an error, keeping its message.
+type taggedError struct {
+	kind error
+	err  error
+}
+
+func tagError(kind error, err error) error {
+	if errors.Is(err, kind) {
+		return err
+	}
+	return &taggedError{kind: kind, err: err}
+}
+
+func (e *taggedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *taggedError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// SmtpReplyError is an unexpected reply from the mail server. Replies in the
+// 4xx range (greylisting, rate limits) are temporary and worth retrying later.
+type SmtpReplyError struct {
+	Code int
+	Msg  string
+}
+
+func (e *SmtpReplyError) Error() string {
+	return fmt.Sprintf("smtp reply %d: %s", e.Code, e.Msg)
+}
+
+func (e *SmtpReplyError) Temporary() bool {
+	return e.Code >= 400 && e.Code < 500
+}
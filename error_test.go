@@ -0,0 +1,164 @@
+package mail_checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test the provider errors match their failure mode and keep their message
+func TestErrorTaxonomy(t *testing.T) {
+	cases := []struct {
+		err     error
+		kind    error
+		message string
+	}{
+		{ErrMicrosoftGetAmscCookieError, ErrSessionTokenMissing, "get amsc cookie fail"},
+		{ErrMicrosoftGetCanaryCookieError, ErrSessionTokenMissing, "get canary cookie fail"},
+		{ErrGoogleGetXsrfTokenError, ErrSessionTokenMissing, "get google xsrf token fail"},
+		{ErrYahooGetCookieError, ErrSessionTokenMissing, "could not detect cookies"},
+		{fmt.Errorf("could not detect value for crumb: %w", ErrYahooGetSessionValue), ErrSessionTokenMissing, "could not detect value for crumb: session value missing"},
+		{ErrMicrosoftIsAvailableMissing, ErrUpstreamSchemaChanged, "isAvailable field missing in response"},
+		{ErrYahooErrorsFieldMissing, ErrUpstreamSchemaChanged, "errors field missing in response"},
+		{ErrYahooUserIdRejected, ErrInvalidFormat, "user id rejected by upstream"},
+		{&AddressError{Address: "a@@b", Reason: "bad"}, ErrInvalidFormat, `invalid email address "a@@b": bad`},
+	}
+	for _, c := range cases {
+		if !errors.Is(c.err, c.kind) {
+			t.Errorf("%v: expected to match %v", c.err, c.kind)
+		}
+		if c.err.Error() != c.message {
+			t.Errorf("expected message %q, got %q", c.message, c.err.Error())
+		}
+	}
+	if errors.Is(ErrYahooGetCookieError, ErrUpstreamSchemaChanged) {
+		t.Errorf("expected ErrYahooGetCookieError not to match ErrUpstreamSchemaChanged")
+	}
+}
+
+// Test checkHttpStatus reports throttling with the delay asked for
+func TestCheckHttpStatusRateLimited(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/api", nil)
+	res := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": {"7"}},
+		Request:    req,
+	}
+	err := checkHttpStatus(res)
+
+	var rateLimitErr *RateLimitError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter != 7*time.Second {
+		t.Fatalf("expected a RateLimitError retrying after 7s, got %v", err)
+	}
+	var statusErr *HttpStatusError
+	if !errors.As(err, &statusErr) || statusErr.Url != "https://example.com/api" {
+		t.Fatalf("expected the HttpStatusError to be wrapped, got %v", err)
+	}
+	if !IsTransient(err) {
+		t.Errorf("expected a rate limit to be transient")
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if delay := parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now); delay != 90*time.Second {
+		t.Errorf("expected a 90s delay from a date, got %v", delay)
+	}
+	if delay := parseRetryAfter("soon", now); delay != 0 {
+		t.Errorf("expected no delay from an invalid value, got %v", delay)
+	}
+
+	res.StatusCode = http.StatusProxyAuthRequired
+	if err = checkHttpStatus(res); !errors.Is(err, ErrProxyAuth) {
+		t.Errorf("expected ErrProxyAuth, got %v", err)
+	}
+}
+
+// Test classifyError tags timeouts, proxy failures and unreadable responses
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		err  error
+		kind error
+	}{
+		{context.DeadlineExceeded, ErrTimeout},
+		{timeoutError{}, ErrTimeout},
+		{json.Unmarshal([]byte("<html>"), &struct{}{}), ErrUpstreamSchemaChanged},
+	}
+	for _, c := range cases {
+		err := classifyError(c.err)
+		if !errors.Is(err, c.kind) || !errors.Is(err, c.err) || err.Error() != c.err.Error() {
+			t.Errorf("%v: expected to be tagged with %v, got %v", c.err, c.kind, err)
+		}
+	}
+	if err := classifyError(context.Canceled); err != context.Canceled {
+		t.Errorf("expected cancellation to be left as is, got %v", err)
+	}
+}
+
+// Test CheckWithError returns the failure of the check
+func TestCheckWithError(t *testing.T) {
+	responses := map[string]func() *http.Response{
+		"no canary": func() *http.Response {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Set-Cookie": {"amsc=testCookie; path=/;"}},
+				Body:       io.NopCloser(strings.NewReader(`<html></html>`)),
+			}
+		},
+		"throttled": func() *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {"30"}},
+				Body:       io.NopCloser(strings.NewReader(``)),
+			}
+		},
+	}
+	expected := map[string]error{
+		"no canary": ErrSessionTokenMissing,
+		"throttled": ErrRateLimited,
+	}
+	for name, response := range responses {
+		checker := &microsoftMail{client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			return response(), nil
+		})}
+		status, err := CheckWithError(context.Background(), checker, "test@hotmail.com")
+		if status.Id != StatusIdCheckError || !errors.Is(err, expected[name]) {
+			t.Errorf("%s: expected StatusIdCheckError and %v, got %v and %v", name, expected[name], status.Id, err)
+		}
+	}
+
+	checker := &microsoftMail{client: newMockClient(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := CheckWithError(ctx, checker, "test@hotmail.com"); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+	if status, err := CheckWithError(context.Background(), checker, "a..b@hotmail.com"); status.Id != StatusIdFormatInvalid || !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected StatusIdFormatInvalid and ErrInvalidFormat, got %v and %v", status.Id, err)
+	}
+}
+
+// Test the retry checker waits as long as the upstream asked
+func TestRetryCheckerRetryAfter(t *testing.T) {
+	checker := NewRetryChecker(&errorChecker{results: func(attempt int) (StatusId, error) {
+		if attempt == 1 {
+			return StatusIdCheckError, &RateLimitError{RetryAfter: 50 * time.Millisecond, Err: &HttpStatusError{StatusCode: http.StatusTooManyRequests}}
+		}
+		return StatusIdLive, nil
+	}}, RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Second})
+
+	startedAt := time.Now()
+	if status := checker.Check("a@example.com"); status.Id != StatusIdLive {
+		t.Fatalf("expected StatusIdLive, got %v", status.Id)
+	}
+	if elapsed := time.Since(startedAt); elapsed < 50*time.Millisecond {
+		t.Errorf("expected to wait the Retry-After delay, waited %v", elapsed)
+	}
+}
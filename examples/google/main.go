@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/ngocchien/mail-checker"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	emails := []string{
+		"chiennn0104@gmail.com",
+		"chiennn0104123123123@gmail.com",
+	}
+	checker := mail_checker.New(mail_checker.MailKindGoogle, mail_checker.Proxy{})
+	for _, email := range emails {
+		status := checker.Check(email)
+		log.Infof("Email: %s, status: %+v", email, status)
+	}
+}
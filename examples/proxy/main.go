@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"github.com/ngocchien/mail-checker"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 3 {
+		log.Fatalf("Usage: %s <proxy url> <email>...", os.Args[0])
+	}
+	proxy, err := mail_checker.ParseProxy(os.Args[1])
+	if err != nil {
+		log.Fatalf("Proxy: %v", err)
+	}
+
+	// A proxy intercepting HTTPS signs with its own root CA.
+	var opts []mail_checker.Option
+	if caFile := os.Getenv("MAIL_CHECKER_ROOT_CA"); caFile != "" {
+		opts = append(opts, mail_checker.WithRootCAFile(caFile))
+	}
+
+	// Check the proxy once at startup rather than getting a check error for
+	// every address.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	latency, err := mail_checker.TestProxy(ctx, proxy, opts...)
+	cancel()
+	if err != nil {
+		log.Fatalf("Proxy %s unusable: %v", proxy.Host, err)
+	}
+	log.Infof("Proxy %s ok, latency: %v", proxy.Host, latency)
+
+	checker, err := mail_checker.NewWithOptions(mail_checker.MailKindAuto, append(opts, mail_checker.WithProxy(proxy))...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, email := range os.Args[2:] {
+		status := checker.Check(email)
+		log.Infof("Email: %s, status: %+v", email, status)
+	}
+}
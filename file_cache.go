@@ -0,0 +1,221 @@
+package mail_checker
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileCache is a Cache persisted to a file, so that results survive restarts.
+// Every Set appends a JSON line to the file and the entries are indexed in
+// memory. The file is compacted, keeping only the unexpired entries, when
+// opened and whenever superseded or expired records make up most of it.
+type FileCache struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	entries map[string]CacheEntry
+	records int
+	logger  Logger
+	now     func() time.Time
+}
+
+// OpenFileCache opens the cache file at path, creating it when missing.
+// Lines that cannot be read, like a record cut short by a crash, are skipped.
+func OpenFileCache(path string) (*FileCache, error) {
+	c := &FileCache{
+		path:    path,
+		entries: make(map[string]CacheEntry),
+		logger:  NopLogger,
+		now:     time.Now,
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	if err := c.compact(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *FileCache) load() error {
+	file, err := os.Open(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		var record fileCacheRecord
+		if json.Unmarshal(line, &record) == nil && record.Key != "" {
+			c.entries[record.Key] = CacheEntry{
+				Email:     record.Email,
+				Kind:      record.Kind,
+				Status:    getStatusById(record.StatusId),
+				Reason:    record.Reason,
+				CheckedAt: record.CheckedAt,
+				ExpiresAt: record.ExpiresAt,
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *FileCache) Get(key string) (entry CacheEntry, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok = c.entries[key]
+	if !ok || !c.now().Before(entry.ExpiresAt) {
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *FileCache) Set(key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		c.logger.Error("Cache the result failed", logKeyPhase, logPhaseCache, logKeyError, ErrFileCacheClosed)
+		return
+	}
+	line, _ := json.Marshal(newFileCacheRecord(key, entry))
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		c.logger.Error("Append to the cache file failed", logKeyPhase, logPhaseCache, "path", c.path, logKeyError, err)
+		return
+	}
+	c.entries[key] = entry
+	c.records++
+
+	if c.records >= fileCacheCompactMinRecords && c.records > fileCacheCompactFactor*len(c.entries) {
+		if err := c.compact(); err != nil {
+			c.logger.Error("Compact the cache file failed", logKeyPhase, logPhaseCache, "path", c.path, logKeyError, err)
+		}
+	}
+}
+
+// SetLogger sets the logger receiving the errors of Set, which has no error
+// to return. The default is NopLogger.
+func (c *FileCache) SetLogger(logger Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if logger == nil {
+		logger = NopLogger
+	}
+	c.logger = logger
+}
+
+// Len returns the number of entries held, expired ones included.
+func (c *FileCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Compact drops the expired entries and rewrites the file with one record
+// per remaining entry.
+func (c *FileCache) Compact() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return ErrFileCacheClosed
+	}
+	return c.compact()
+}
+
+func (c *FileCache) compact() (err error) {
+	now := c.now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.ExpiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	// Write the entries to a temporary file renamed over the cache file, so
+	// that a crash leaves either the old or the new file.
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for key, entry := range c.entries {
+		if err = encoder.Encode(newFileCacheRecord(key, entry)); err != nil {
+			return err
+		}
+	}
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(fileCacheFileMode); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), c.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(c.path, os.O_WRONLY|os.O_APPEND, fileCacheFileMode)
+	if err != nil {
+		return err
+	}
+	if c.file != nil {
+		c.file.Close()
+	}
+	c.file = file
+	c.records = len(c.entries)
+	return nil
+}
+
+// Close closes the cache file. The entries already written stay on disk.
+func (c *FileCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return ErrFileCacheClosed
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+func newFileCacheRecord(key string, entry CacheEntry) fileCacheRecord {
+	return fileCacheRecord{
+		Key:       key,
+		Email:     entry.Email,
+		Kind:      entry.Kind,
+		StatusId:  entry.Status.Id,
+		Reason:    entry.Reason,
+		CheckedAt: entry.CheckedAt,
+		ExpiresAt: entry.ExpiresAt,
+	}
+}
@@ -0,0 +1,199 @@
+package mail_checker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test the file cache keeps its entries across a reopen
+func TestFileCacheReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.cache")
+	cache, err := OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	now := time.Now()
+	cache.Set("a@yahoo.com", CacheEntry{Email: "a@yahoo.com", Kind: MailKindYahoo, Status: getStatusById(StatusIdLive), Reason: "IDENTIFIER_EXISTS", CheckedAt: now, ExpiresAt: now.Add(time.Hour)})
+	cache.Set("b@yahoo.com", CacheEntry{Email: "b@yahoo.com", Kind: MailKindYahoo, Status: getStatusById(StatusIdNotExists), CheckedAt: now, ExpiresAt: now.Add(time.Hour)})
+	cache.Set("c@yahoo.com", CacheEntry{Email: "c@yahoo.com", Kind: MailKindYahoo, Status: getStatusById(StatusIdLive), CheckedAt: now, ExpiresAt: now.Add(-time.Second)})
+	if err = cache.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	cache, err = OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer cache.Close()
+
+	entry, ok := cache.Get("a@yahoo.com")
+	if !ok || entry.Status.Id != StatusIdLive || entry.Status.Name != StatusNameLive || entry.Kind != MailKindYahoo || entry.Reason != "IDENTIFIER_EXISTS" {
+		t.Fatalf("unexpected entry for a@yahoo.com: %+v", entry)
+	}
+	if !entry.CheckedAt.Equal(now) {
+		t.Fatalf("expected the check time to be kept, got %v", entry.CheckedAt)
+	}
+	if entry, ok = cache.Get("b@yahoo.com"); !ok || entry.Status.Id != StatusIdNotExists {
+		t.Fatalf("unexpected entry for b@yahoo.com: %+v", entry)
+	}
+	if _, ok = cache.Get("c@yahoo.com"); ok {
+		t.Fatalf("expected the expired entry to be dropped")
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("expected 2 entries after compaction, got %d", cache.Len())
+	}
+}
+
+// Test compaction rewrites the file with one record per entry
+func TestFileCacheCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.cache")
+	cache, err := OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer cache.Close()
+
+	expiresAt := time.Now().Add(time.Hour)
+	for i := 0; i < 10; i++ {
+		cache.Set("a@yahoo.com", CacheEntry{Email: "a@yahoo.com", Status: getStatusById(StatusIdLive), ExpiresAt: expiresAt})
+	}
+	if lines := countLines(t, path); lines != 10 {
+		t.Fatalf("expected 10 records before compaction, got %d", lines)
+	}
+	if err = cache.Compact(); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if lines := countLines(t, path); lines != 1 {
+		t.Fatalf("expected 1 record after compaction, got %d", lines)
+	}
+
+	// Records appended after a compaction still land in the cache file.
+	cache.Set("b@yahoo.com", CacheEntry{Email: "b@yahoo.com", Status: getStatusById(StatusIdLive), ExpiresAt: expiresAt})
+	if lines := countLines(t, path); lines != 2 {
+		t.Fatalf("expected 2 records, got %d", lines)
+	}
+}
+
+// Test the file cache compacts itself once superseded records pile up
+func TestFileCacheAutoCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.cache")
+	cache, err := OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer cache.Close()
+
+	expiresAt := time.Now().Add(time.Hour)
+	for i := 0; i < fileCacheCompactMinRecords; i++ {
+		email := fmt.Sprintf("user%d@yahoo.com", i%10)
+		cache.Set(email, CacheEntry{Email: email, Status: getStatusById(StatusIdLive), ExpiresAt: expiresAt})
+	}
+	if lines := countLines(t, path); lines != 10 {
+		t.Fatalf("expected the file to be compacted to 10 records, got %d", lines)
+	}
+}
+
+// Test a record cut short by a crash is skipped
+func TestFileCacheTruncatedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.cache")
+	expiresAt := time.Now().Add(time.Hour).Format(time.RFC3339Nano)
+	content := `{"key":"a@yahoo.com","email":"a@yahoo.com","kind":"yahoo","status_id":1,"checked_at":"2026-01-01T00:00:00Z","expires_at":"` + expiresAt + `"}` + "\n" +
+		`{"key":"b@yahoo.com","email":"b@yahoo.com","kind":"ya`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	cache, err := OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer cache.Close()
+	if _, ok := cache.Get("a@yahoo.com"); !ok {
+		t.Fatalf("expected a@yahoo.com to be loaded")
+	}
+	if cache.Len() != 1 {
+		t.Fatalf("expected 1 entry, got %d", cache.Len())
+	}
+}
+
+// Test a line longer than any record, like garbage from a disk error, is
+// skipped with the records around it kept
+func TestFileCacheOverlongLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.cache")
+	expiresAt := time.Now().Add(time.Hour).Format(time.RFC3339Nano)
+	record := func(email string) string {
+		return `{"key":"` + email + `","email":"` + email + `","kind":"yahoo","status_id":1,"checked_at":"2026-01-01T00:00:00Z","expires_at":"` + expiresAt + `"}` + "\n"
+	}
+	content := record("a@yahoo.com") + strings.Repeat("x", 1<<20) + "\n" + record("b@yahoo.com")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	cache, err := OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer cache.Close()
+	if cache.Len() != 2 {
+		t.Fatalf("expected the 2 records around the long line, got %d", cache.Len())
+	}
+}
+
+// Test the file cache under concurrent checks, as the store of a cached checker
+func TestFileCacheConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.cache")
+	cache, err := OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	var calls atomic.Int32
+	stub := &stubChecker{kind: MailKindYahoo, checkFunc: func(ctx context.Context, email string) StatusId {
+		calls.Add(1)
+		return StatusIdLive
+	}}
+	checker := NewCachedChecker(stub, cache, CachePolicy{})
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				checker.Check(fmt.Sprintf("user%d@yahoo.com", i))
+			}
+		}()
+	}
+	wg.Wait()
+	cache.Close()
+
+	// A second run only hits the network for unseen addresses.
+	cache, err = OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer cache.Close()
+	before := calls.Load()
+	checker = NewCachedChecker(stub, cache, CachePolicy{})
+	for i := 0; i < 51; i++ {
+		checker.Check(fmt.Sprintf("user%d@yahoo.com", i))
+	}
+	if calls.Load()-before != 1 {
+		t.Fatalf("expected 1 upstream check on the second run, got %d", calls.Load()-before)
+	}
+}
+
+func countLines(t *testing.T, path string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return bytes.Count(content, []byte("\n"))
+}
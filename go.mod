@@ -2,9 +2,11 @@ module github.com/ngocchien/mail-checker
 
 go 1.21.6
 
-require github.com/sirupsen/logrus v1.9.3
-
 require (
-	github.com/google/go-querystring v1.1.0 // indirect
-	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	github.com/google/go-querystring v1.1.0
+	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/net v0.21.0
+	golang.org/x/text v0.14.0
 )
+
+require golang.org/x/sys v0.17.0 // indirect
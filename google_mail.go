@@ -0,0 +1,191 @@
+package mail_checker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+type googleMail struct {
+	client    *http.Client
+	limiter   *RateLimiter
+	session   *sessionCache[googleSession]
+	endpoints *Endpoints
+	logger    Logger
+	timeouts  phaseTimeouts
+}
+
+func newGoogleMail(client *http.Client) *googleMail {
+	return &googleMail{
+		client:  client,
+		limiter: limiterFor(MailKindGoogle),
+		session: newSessionCache[googleSession](DefaultSessionPolicy),
+	}
+}
+
+func (g *googleMail) apply(o options) *googleMail {
+	g.limiter = o.rateLimiter(MailKindGoogle)
+	g.session = newSessionCache[googleSession](o.sessionPolicy())
+	g.endpoints = o.endpoints
+	g.logger = o.logger
+	g.timeouts = o.phaseTimeouts()
+	return g
+}
+
+func (g *googleMail) Check(email string) (status Status) {
+	return g.CheckContext(context.Background(), email)
+}
+
+func (g *googleMail) CheckContext(ctx context.Context, email string) (status Status) {
+	return g.CheckDetail(ctx, email).Status
+}
+
+func (g *googleMail) CheckDetail(ctx context.Context, email string) (result CheckResult) {
+	result = newCheckResult(MailKindGoogle, email)
+	local, _, err := result.normalize()
+	if err == nil {
+		err = checkLocalPartRule(MailKindGoogle, email, local)
+	}
+	logger := checkLogger(g.logger, MailKindGoogle, result.Email)
+	if err != nil {
+		logger.Debug("Invalid address", logKeyPhase, logPhaseValidate, logKeyError, redactError(err))
+		return result.finish(StatusIdFormatInvalid, "", err)
+	}
+
+	username, _, _ := strings.Cut(local, "+")
+	probe := func(ctx context.Context, result CheckResult, session googleSession) (CheckResult, bool) {
+		return g.checkUsername(ctx, result, session, username)
+	}
+	return runPhases(ctx, result, logger, g.session, g.getSession, probe, g.timeouts)
+}
+
+// checkUsername probes the username with the session. The second value
+// reports that the upstream rejected the session.
+func (g *googleMail) checkUsername(ctx context.Context, result CheckResult, session googleSession, username string) (CheckResult, bool) {
+	freq, _ := json.Marshal([]interface{}{username, 1})
+	data := url.Values{}
+	data.Set("f.req", string(freq))
+	data.Set("at", session.Xsrf)
+	logger := checkLogger(g.logger, MailKindGoogle, result.Email)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.endpoints.probeOr(googleUrlCheckAvailable), strings.NewReader(data.Encode()))
+	if err != nil {
+		return result.finish(StatusIdCheckError, "", err), false
+	}
+	req.Header.Set("Content-Type", `application/x-www-form-urlencoded;charset=UTF-8`)
+	req.Header.Set("Cookie", googleCookieSession+"="+session.Cookie)
+	req.Header.Set("X-Same-Domain", "1")
+
+	if err = g.limiter.Wait(ctx); err != nil {
+		return result.finish(StatusIdCheckError, "", err), false
+	}
+	res, err := g.client.Do(req)
+	if err != nil {
+		logger.Error("Exec request failed", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
+	}
+	defer closeBody(res.Body)
+	if isSessionRejectedStatus(res) {
+		return result.finish(StatusIdCheckError, "", newHttpStatusError(res)), true
+	}
+	if err = checkHttpStatus(res); err != nil {
+		logger.Error("Unexpected response", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
+	}
+
+	bodyBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		logger.Error("Read response body failed", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
+	}
+
+	code, err := g.parseAvailability(string(bodyBytes))
+	if err != nil {
+		logger.Warn("Parse response failed", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), true
+	}
+
+	reason := fmt.Sprintf("%s=%d", googleKeyUsernameAvailable, code)
+	switch code {
+	case googleCodeUsernameAvailable:
+		return result.finish(StatusIdNotExists, reason, nil), false
+	case googleCodeUsernameTaken:
+		return result.finish(StatusIdLive, reason, nil), false
+	}
+	return result.finish(StatusIdCheckError, reason, ErrGoogleUnexpectedResponse), false
+}
+
+func (g *googleMail) parseAvailability(body string) (code int, err error) {
+	body = strings.TrimSpace(strings.TrimPrefix(body, googleResponsePrefix))
+
+	var entries [][]interface{}
+	if err = json.Unmarshal([]byte(body), &entries); err != nil {
+		return code, fmt.Errorf("%w: %s", ErrGoogleUnexpectedResponse, err.Error())
+	}
+
+	for _, entry := range entries {
+		if len(entry) < 2 || entry[0] != googleKeyUsernameAvailable {
+			continue
+		}
+		value, ok := entry[1].(float64)
+		if !ok {
+			break
+		}
+		return int(value), nil
+	}
+	return code, ErrGoogleUnexpectedResponse
+}
+
+func (g *googleMail) getSessionCookie(res *http.Response) (err error, cookie string) {
+	for _, c := range res.Cookies() {
+		if c.Name == googleCookieSession && c.Value != "" {
+			return nil, c.Value
+		}
+	}
+	return ErrGoogleGetSessionCookieError, cookie
+}
+
+func (g *googleMail) getXsrfToken(html string) (err error, token string) {
+	re := regexp.MustCompile(`"SNlM0e":"(.*?)"`)
+	matches := re.FindStringSubmatch(html)
+	if len(matches) == 0 || matches[1] == "" {
+		return ErrGoogleGetXsrfTokenError, token
+	}
+	return nil, matches[1]
+}
+
+func (g *googleMail) getSession(ctx context.Context) (session googleSession, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.endpoints.bootstrapOr(googleUrlSignup), nil)
+	if err != nil {
+		return session, err
+	}
+	if err = g.limiter.Wait(ctx); err != nil {
+		return session, err
+	}
+	res, err := g.client.Do(req)
+	if err != nil {
+		return session, err
+	}
+	defer closeBody(res.Body)
+	if err = checkHttpStatus(res); err != nil {
+		return session, err
+	}
+
+	if err, session.Cookie = g.getSessionCookie(res); err != nil {
+		return session, err
+	}
+
+	htmlBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return session, err
+	}
+	if err, session.Xsrf = g.getXsrfToken(string(htmlBytes)); err != nil {
+		return session, err
+	}
+	return session, nil
+}
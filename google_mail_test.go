@@ -0,0 +1,150 @@
+package mail_checker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newStandInClient returns a client that sends every request to the stand-in server
+func newStandInClient(server *httptest.Server) *http.Client {
+	target, _ := url.Parse(server.URL)
+	return &http.Client{
+		Transport: &mockTransport{
+			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+				req = req.Clone(req.Context())
+				req.URL.Scheme = target.Scheme
+				req.URL.Host = target.Host
+				return server.Client().Transport.RoundTrip(req)
+			},
+		},
+	}
+}
+
+// newGoogleStandIn serves the Google signup page and the username availability endpoint
+func newGoogleStandIn(t *testing.T, taken map[string]bool) *httptest.Server {
+	signup, _ := url.Parse(googleUrlSignup)
+	check, _ := url.Parse(googleUrlCheckAvailable)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(signup.Path, func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: googleCookieSession, Value: "testCookie"})
+		io.WriteString(w, `<script>window.WIZ_global_data = {"FdrFJe":"-1","SNlM0e":"testXsrf"};</script>`)
+	})
+	mux.HandleFunc(check.Path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if c, err := r.Cookie(googleCookieSession); err != nil || c.Value != "testCookie" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.FormValue("at") != "testXsrf" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var freq []interface{}
+		if err := json.Unmarshal([]byte(r.FormValue("f.req")), &freq); err != nil || len(freq) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		code := googleCodeUsernameAvailable
+		if taken[freq[0].(string)] {
+			code = googleCodeUsernameTaken
+		}
+		io.WriteString(w, googleResponsePrefix+"\n"+`[["gf.uar",`+strconv.Itoa(code)+`,[]]]`)
+	})
+	return httptest.NewServer(mux)
+}
+
+// Test the Check function against the Google stand-in
+func TestGoogleCheck(t *testing.T) {
+	server := newGoogleStandIn(t, map[string]bool{"taken.user": true})
+	defer server.Close()
+
+	g := &googleMail{client: newStandInClient(server)}
+
+	result := g.CheckDetail(context.Background(), "taken.user@gmail.com")
+	if result.Status.Id != StatusIdLive {
+		t.Fatalf("expected StatusIdLive, got %v (%v)", result.Status.Id, result.Err)
+	}
+	if result.Kind != MailKindGoogle {
+		t.Fatalf("expected MailKindGoogle, got %v", result.Kind)
+	}
+	if result.Reason != "gf.uar=2" {
+		t.Fatalf("expected reason 'gf.uar=2', got %q", result.Reason)
+	}
+
+	status := g.Check("free.user@gmail.com")
+	if status.Id != StatusIdNotExists {
+		t.Fatalf("expected StatusIdNotExists, got %v", status.Id)
+	}
+
+	status = g.Check("invalid-email-format")
+	if status.Id != StatusIdFormatInvalid {
+		t.Fatalf("expected StatusIdFormatInvalid, got %v", status.Id)
+	}
+}
+
+// Test the Check function when the signup page has no session material
+func TestGoogleCheck_SessionError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `<html></html>`)
+	}))
+	defer server.Close()
+
+	g := &googleMail{client: newStandInClient(server)}
+	result := g.CheckDetail(context.Background(), "testuser@gmail.com")
+	if result.Status.Id != StatusIdCheckError {
+		t.Fatalf("expected StatusIdCheckError, got %v", result.Status.Id)
+	}
+	if result.Err != ErrGoogleGetSessionCookieError {
+		t.Fatalf("expected ErrGoogleGetSessionCookieError, got %v", result.Err)
+	}
+}
+
+// Test the getXsrfToken function
+func TestGoogleGetXsrfToken(t *testing.T) {
+	g := &googleMail{}
+	err, token := g.getXsrfToken(`{"SNlM0e":"abc"}`)
+	if err != nil || token != "abc" {
+		t.Fatalf("expected 'abc', got %q (%v)", token, err)
+	}
+	err, _ = g.getXsrfToken(`{"SNlM0e":""}`)
+	if err != ErrGoogleGetXsrfTokenError {
+		t.Fatalf("expected ErrGoogleGetXsrfTokenError, got %v", err)
+	}
+}
+
+// Test the parseAvailability function
+func TestGoogleParseAvailability(t *testing.T) {
+	g := &googleMail{}
+	cases := []struct {
+		body    string
+		code    int
+		wantErr bool
+	}{
+		{body: ")]}'\n[[\"gf.uar\",1]]", code: 1},
+		{body: ")]}'\n[[\"gf.ttu\",0],[\"gf.uar\",2,[]]]", code: 2},
+		{body: ")]}'\n[[\"gf.ttu\",0]]", wantErr: true},
+		{body: "<html>", wantErr: true},
+	}
+	for _, c := range cases {
+		code, err := g.parseAvailability(c.body)
+		if (err != nil) != c.wantErr {
+			t.Fatalf("body %q: unexpected error %v", c.body, err)
+		}
+		if !c.wantErr && code != c.code {
+			t.Fatalf("body %q: expected code %d, got %d", c.body, c.code, code)
+		}
+		if c.wantErr && !strings.Contains(err.Error(), ErrGoogleUnexpectedResponse.Error()) {
+			t.Fatalf("body %q: expected ErrGoogleUnexpectedResponse, got %v", c.body, err)
+		}
+	}
+}
@@ -1,31 +1,112 @@
 package mail_checker
 
 import (
-	log "github.com/sirupsen/logrus"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
-	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Checker interface {
 	Check(email string) (status Status)
+	CheckContext(ctx context.Context, email string) (status Status)
+	CheckDetail(ctx context.Context, email string) (result CheckResult)
 }
 
-func makeHttpClient(proxy Proxy) *http.Client {
-	c := http.Client{
-		Timeout: httpClientTimeoutDefault,
+// CheckWithError checks the email with the checker and returns the error
+// behind a StatusIdCheckError or StatusIdFormatInvalid status.
+func CheckWithError(ctx context.Context, checker Checker, email string) (status Status, err error) {
+	result := checker.CheckDetail(ctx, email)
+	return result.Status, result.Err
+}
+
+func makeHttpClient(proxy Proxy, dial dialOptions, policy TransportPolicy) (*http.Client, error) {
+	dialer := newDialer(dial)
+	transport := newTransport(policy, dialer)
+	if err := setTransportProxy(transport, dialer, proxy); err != nil {
+		return nil, err
 	}
-	if proxy.Host != "" {
-		p := &url.URL{
-			Host: proxy.Host,
-		}
-		if proxy.User != "" && proxy.Password != "" {
-			p.User = url.UserPassword(proxy.User, proxy.Password)
-		}
-		c.Transport = &http.Transport{
-			Proxy: http.ProxyURL(p),
+	return &http.Client{
+		Timeout:   httpClientTimeoutDefault,
+		Transport: transport,
+	}, nil
+}
+
+func checkHttpStatus(res *http.Response) error {
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{
+			RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+			Err:        newHttpStatusError(res),
 		}
+	case res.StatusCode == http.StatusProxyAuthRequired:
+		return tagError(ErrProxyAuth, newHttpStatusError(res))
+	case res.StatusCode >= http.StatusInternalServerError:
+		return newHttpStatusError(res)
 	}
-	return &c
+	return nil
+}
+
+// parseRetryAfter reads a Retry-After header, given in seconds or as a date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
+
+// classifyError tags err with the failure mode it reveals, if any.
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return tagError(ErrTimeout, err)
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return tagError(ErrUpstreamSchemaChanged, err)
+	}
+	return err
+}
+
+func newHttpStatusError(res *http.Response) *HttpStatusError {
+	statusErr := &HttpStatusError{StatusCode: res.StatusCode}
+	if res.Request != nil {
+		statusErr.Url = res.Request.URL.String()
+	}
+	return statusErr
+}
+
+func newCheckResult(kind MailKind, email string) CheckResult {
+	return CheckResult{
+		Input:     email,
+		Email:     strings.TrimSpace(email),
+		Kind:      kind,
+		Attempts:  1,
+		StartedAt: time.Now(),
+	}
+}
+
+func (r CheckResult) finish(id StatusId, reason string, err error) CheckResult {
+	r.Status = getStatusById(id)
+	r.Reason = reason
+	r.Err = classifyError(err)
+	r.FinishedAt = time.Now()
+	r.Latency = r.FinishedAt.Sub(r.StartedAt)
+	return r
 }
 
 func getStatusById(id StatusId) (status Status) {
@@ -60,23 +141,32 @@ func getStatusById(id StatusId) (status Status) {
 			Id:   id,
 			Name: StatusNameFormatInvalid,
 		}
+	case StatusIdUnsupported:
+		status = Status{
+			Id:   id,
+			Name: StatusNameUnsupported,
+		}
+	case StatusIdMailboxFull:
+		status = Status{
+			Id:   id,
+			Name: StatusNameMailboxFull,
+		}
+	case StatusIdCatchAll:
+		status = Status{
+			Id:   id,
+			Name: StatusNameCatchAll,
+		}
 	}
 	return status
 }
 
+// New returns a checker of the kind sending its requests through the proxy,
+// or nil for an unknown kind or an invalid proxy. NewWithOptions offers more
+// settings and reports why the checker cannot be built.
 func New(mailKind MailKind, proxy Proxy) Checker {
-	client := makeHttpClient(proxy)
-	switch mailKind {
-	case MailKindMicrosoft:
-		return &microsoftMail{
-			client: client,
-		}
-	case MailKindYahoo:
-		return &yahooMail{
-			client: client,
-		}
-	default:
-		log.Errorf("The mail kind input invalid")
+	checker, err := NewWithOptions(mailKind, WithProxy(proxy))
+	if err != nil {
+		return nil
 	}
-	return nil
+	return checker
 }
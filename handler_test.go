@@ -18,7 +18,10 @@ func TestMakeHttpClient(t *testing.T) {
 	proxy := Proxy{
 		Host: "127.0.0.1:8080",
 	}
-	client := makeHttpClient(proxy)
+	client, err := makeHttpClient(proxy, dialOptions{}, DefaultTransportPolicy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if client.Timeout != httpClientTimeoutDefault {
 		t.Errorf("expected timeout %v, got %v", httpClientTimeoutDefault, client.Timeout)
 	}
@@ -42,3 +45,37 @@ func TestNew(t *testing.T) {
 		t.Errorf("expected a non-nil checker")
 	}
 }
+
+// Test New function for the Google mail kind
+func TestNewGoogle(t *testing.T) {
+	checker := New(MailKindGoogle, Proxy{})
+	if _, ok := checker.(*googleMail); !ok {
+		t.Errorf("expected a googleMail checker, got %T", checker)
+	}
+}
+
+// Test New function for the auto mail kind
+func TestNewAuto(t *testing.T) {
+	checker := New(MailKindAuto, Proxy{})
+	router, ok := checker.(*routerMail)
+	if !ok {
+		t.Fatalf("expected a routerMail checker, got %T", checker)
+	}
+	for _, kind := range []MailKind{MailKindMicrosoft, MailKindYahoo, MailKindGoogle} {
+		if router.checkers[kind] == nil {
+			t.Errorf("expected a checker for %v", kind)
+		}
+	}
+}
+
+// Test New function for the SMTP mail kind
+func TestNewSmtp(t *testing.T) {
+	checker := New(MailKindSmtp, Proxy{})
+	s, ok := checker.(*smtpMail)
+	if !ok {
+		t.Fatalf("expected a smtpMail checker, got %T", checker)
+	}
+	if s.port != smtpPortDefault || s.lookupMX == nil || s.dial == nil {
+		t.Errorf("expected default SMTP settings, got %+v", s)
+	}
+}
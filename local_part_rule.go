@@ -0,0 +1,105 @@
+package mail_checker
+
+import (
+	"strings"
+)
+
+type localPartRule func(local string) (reason string)
+
+var localPartRules = map[MailKind]localPartRule{
+	MailKindMicrosoft: microsoftLocalPartRule,
+	MailKindYahoo:     yahooLocalPartRule,
+	MailKindGoogle:    googleLocalPartRule,
+}
+
+// ValidateForProvider checks the email syntax and the username rules of the
+// provider, without any network call. Kinds without offline rules only get
+// the syntax check.
+func ValidateForProvider(kind MailKind, email string) error {
+	local, _, err := normalizeAddress(email)
+	if err != nil {
+		return err
+	}
+	return checkLocalPartRule(kind, email, local)
+}
+
+func checkLocalPartRule(kind MailKind, email string, local string) error {
+	rule, ok := localPartRules[kind]
+	if !ok {
+		return nil
+	}
+	if reason := rule(local); reason != "" {
+		return &AddressError{Address: strings.TrimSpace(email), Reason: reason}
+	}
+	return nil
+}
+
+// Outlook, Hotmail and Live usernames: letters, digits, '.', '_' and '-',
+// starting with a letter, without consecutive or trailing dots. A "+tag"
+// suffix is allowed for plus addressing.
+func microsoftLocalPartRule(local string) string {
+	username, _, _ := strings.Cut(local, "+")
+	if username == "" || len(username) > addressLocalMaxLength {
+		return "microsoft username must have 1-64 characters"
+	}
+	if !isAsciiLetter(rune(username[0])) {
+		return "microsoft username must start with a letter"
+	}
+	if strings.Contains(username, "..") || strings.HasSuffix(username, ".") {
+		return "microsoft username cannot have consecutive or trailing dots"
+	}
+	if !onlyRunes(username, "._-") {
+		return "microsoft username can only contain letters, numbers, '.', '_' and '-'"
+	}
+	return ""
+}
+
+// Yahoo and AOL usernames: 4-32 characters, starting with a letter, made of
+// letters, digits, underscores and at most one dot, not ending with '.' or '_'.
+func yahooLocalPartRule(local string) string {
+	if len(local) < yahooUsernameMinLength || len(local) > yahooUsernameMaxLength {
+		return "yahoo username must have 4-32 characters"
+	}
+	if !isAsciiLetter(rune(local[0])) {
+		return "yahoo username must start with a letter"
+	}
+	if strings.Count(local, ".") > 1 {
+		return "yahoo username can have only one dot"
+	}
+	if strings.HasSuffix(local, ".") || strings.HasSuffix(local, "_") {
+		return "yahoo username cannot end with '.' or '_'"
+	}
+	if !onlyRunes(local, "._") {
+		return "yahoo username can only contain letters, numbers, '_' and one '.'"
+	}
+	return ""
+}
+
+// Gmail usernames: 6-30 letters, digits and dots, without leading, trailing
+// or consecutive dots. A "+tag" suffix is allowed for plus addressing.
+func googleLocalPartRule(local string) string {
+	username, _, _ := strings.Cut(local, "+")
+	if len(username) < googleUsernameMinLength || len(username) > googleUsernameMaxLength {
+		return "gmail username must have 6-30 characters"
+	}
+	if strings.HasPrefix(username, ".") || strings.HasSuffix(username, ".") || strings.Contains(username, "..") {
+		return "gmail username cannot have leading, trailing or consecutive dots"
+	}
+	if !onlyRunes(username, ".") {
+		return "gmail username can only contain letters, numbers and '.'"
+	}
+	return ""
+}
+
+func isAsciiLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func onlyRunes(s string, extra string) bool {
+	for _, r := range s {
+		if !isAsciiLetter(r) && !(r >= '0' && r <= '9') && !strings.ContainsRune(extra, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package mail_checker
+
+import (
+	"errors"
+	"testing"
+)
+
+// Test ValidateForProvider against each provider's username rules
+func TestValidateForProvider(t *testing.T) {
+	cases := []struct {
+		kind  MailKind
+		email string
+		valid bool
+	}{
+		{MailKindYahoo, "john.doe_1@yahoo.com", true},
+		{MailKindYahoo, "abcd@yahoo.com", true},
+		{MailKindYahoo, "abc@yahoo.com", false},
+		{MailKindYahoo, "a234567890123456789012345678901234@yahoo.com", false},
+		{MailKindYahoo, "1john@yahoo.com", false},
+		{MailKindYahoo, "john.d.oe@yahoo.com", false},
+		{MailKindYahoo, "john_@yahoo.com", false},
+		{MailKindYahoo, "john-doe@yahoo.com", false},
+
+		{MailKindMicrosoft, "john.doe-1_x@outlook.com", true},
+		{MailKindMicrosoft, "j@hotmail.com", true},
+		{MailKindMicrosoft, "john+news@outlook.com", true},
+		{MailKindMicrosoft, "1john@outlook.com", false},
+		{MailKindMicrosoft, "john..doe@outlook.com", false},
+		{MailKindMicrosoft, "john.@outlook.com", false},
+		{MailKindMicrosoft, "john!doe@outlook.com", false},
+		{MailKindMicrosoft, `"john doe"@outlook.com`, false},
+
+		{MailKindGoogle, "john.doe@gmail.com", true},
+		{MailKindGoogle, "johndoe+tag@gmail.com", true},
+		{MailKindGoogle, "123456@gmail.com", true},
+		{MailKindGoogle, "john@gmail.com", false},
+		{MailKindGoogle, "a234567890123456789012345678901@gmail.com", false},
+		{MailKindGoogle, "john_doe@gmail.com", false},
+		{MailKindGoogle, "john..doe@gmail.com", false},
+		{MailKindGoogle, ".johndoe@gmail.com", false},
+
+		{MailKindSmtp, "a@example.com", true},
+		{MailKindSmtp, "a@@example.com", false},
+	}
+	for _, c := range cases {
+		err := ValidateForProvider(c.kind, c.email)
+		if c.valid && err != nil {
+			t.Errorf("%s %s: expected valid, got %v", c.kind, c.email, err)
+		}
+		var addrErr *AddressError
+		if !c.valid && !errors.As(err, &addrErr) {
+			t.Errorf("%s %s: expected an AddressError, got %v", c.kind, c.email, err)
+		}
+	}
+}
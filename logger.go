@@ -0,0 +1,124 @@
+package mail_checker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"log/slog"
+)
+
+// Logger receives the logs of a checker. The arguments after the message are
+// alternating keys and values, like with log/slog: a *slog.Logger is a
+// Logger. Checkers log the provider, the phase of the check and a hash of the
+// address, never the address itself.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// NopLogger discards every log. It is the logger of the checkers built
+// without WithLogger.
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...any) {}
+func (nopLogger) Info(msg string, args ...any)  {}
+func (nopLogger) Warn(msg string, args ...any)  {}
+func (nopLogger) Error(msg string, args ...any) {}
+
+// NewSlogLogger returns a Logger writing to the slog logger, or to the default
+// slog logger when nil.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		return slogLogger{logger: slog.Default()}
+	}
+	return slogLogger{logger: logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Debug(msg string, args ...any) { l.logger.Debug(msg, args...) }
+func (l slogLogger) Info(msg string, args ...any)  { l.logger.Info(msg, args...) }
+func (l slogLogger) Warn(msg string, args ...any)  { l.logger.Warn(msg, args...) }
+func (l slogLogger) Error(msg string, args ...any) { l.logger.Error(msg, args...) }
+
+// NewLogrusLogger returns a Logger writing to the logrus logger, with the
+// key-value arguments as fields, or to the standard logrus logger when nil.
+func NewLogrusLogger(logger logrus.FieldLogger) Logger {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return logrusLogger{logger: logger}
+}
+
+type logrusLogger struct {
+	logger logrus.FieldLogger
+}
+
+func (l logrusLogger) Debug(msg string, args ...any) { l.entry(args).Debug(msg) }
+func (l logrusLogger) Info(msg string, args ...any)  { l.entry(args).Info(msg) }
+func (l logrusLogger) Warn(msg string, args ...any)  { l.entry(args).Warn(msg) }
+func (l logrusLogger) Error(msg string, args ...any) { l.entry(args).Error(msg) }
+
+func (l logrusLogger) entry(args []any) *logrus.Entry {
+	fields := make(logrus.Fields, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fields[logrusBadKey] = args[i]
+			break
+		}
+		fields[fmt.Sprint(args[i])] = args[i+1]
+	}
+	return l.logger.WithFields(fields)
+}
+
+// fieldsLogger adds fields to every log of a logger.
+type fieldsLogger struct {
+	logger Logger
+	fields []any
+}
+
+func (l fieldsLogger) Debug(msg string, args ...any) { l.logger.Debug(msg, l.with(args)...) }
+func (l fieldsLogger) Info(msg string, args ...any)  { l.logger.Info(msg, l.with(args)...) }
+func (l fieldsLogger) Warn(msg string, args ...any)  { l.logger.Warn(msg, l.with(args)...) }
+func (l fieldsLogger) Error(msg string, args ...any) { l.logger.Error(msg, l.with(args)...) }
+
+func (l fieldsLogger) with(args []any) []any {
+	return append(l.fields[:len(l.fields):len(l.fields)], args...)
+}
+
+// checkLogger returns the logger of a check by the provider, with the
+// provider and the hashed address as fields.
+func checkLogger(logger Logger, kind MailKind, email string) Logger {
+	if logger == nil || logger == NopLogger {
+		return NopLogger
+	}
+	fields := []any{logKeyProvider, string(kind)}
+	if email != "" {
+		fields = append(fields, logKeyEmailHash, hashEmail(email))
+	}
+	return fieldsLogger{logger: logger, fields: fields}
+}
+
+// hashEmail returns a short digest identifying the address in the logs.
+func hashEmail(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:logEmailHashLen])
+}
+
+// redactError returns what may be logged of err: the reason of an
+// *AddressError, without the address.
+func redactError(err error) any {
+	var addrErr *AddressError
+	if errors.As(err, &addrErr) {
+		return addrErr.Reason
+	}
+	return err
+}
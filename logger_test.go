@@ -0,0 +1,76 @@
+package mail_checker
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Test checkers log structured fields to a slog logger, without the address
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	checker, err := NewWithOptions(MailKindGoogle, WithLogger(NewSlogLogger(logger)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checker.Check("abc@gmail.com")
+
+	var record map[string]any
+	if err = json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON log record, got %q", buf.String())
+	}
+	if record["provider"] != "google" || record["phase"] != logPhaseValidate || record["level"] != "DEBUG" {
+		t.Errorf("unexpected log record: %v", record)
+	}
+	if record["email_hash"] != hashEmail("abc@gmail.com") {
+		t.Errorf("expected the hashed address, got %v", record["email_hash"])
+	}
+	if strings.Contains(buf.String(), "abc@gmail.com") {
+		t.Errorf("expected the address not to be logged, got %q", buf.String())
+	}
+}
+
+// Test the logrus adapter turns the arguments into fields
+func TestLogrusLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base := logrus.New()
+	base.SetOutput(&buf)
+	base.SetFormatter(&logrus.JSONFormatter{})
+
+	logger := checkLogger(NewLogrusLogger(base), MailKindYahoo, "a@yahoo.com")
+	logger.Warn("Parse response failed", logKeyPhase, logPhaseProbe, "dangling")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON log record, got %q", buf.String())
+	}
+	expected := map[string]any{
+		"msg":           "Parse response failed",
+		"level":         "warning",
+		logKeyProvider:  "yahoo",
+		logKeyPhase:     logPhaseProbe,
+		logKeyEmailHash: hashEmail("a@yahoo.com"),
+		logrusBadKey:    "dangling",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("%s: expected %v, got %v", key, value, record[key])
+		}
+	}
+}
+
+// Test checkers are silent by default
+func TestNopLoggerDefault(t *testing.T) {
+	checker := New(MailKindAuto, Proxy{})
+	if logger := checker.(*routerMail).logger; logger != nil && logger != NopLogger {
+		t.Errorf("expected no logger, got %T", logger)
+	}
+	if logger := checkLogger(nil, MailKindAuto, "a@example.org"); logger != NopLogger {
+		t.Errorf("expected NopLogger, got %T", logger)
+	}
+}
@@ -0,0 +1,75 @@
+package mail_checker
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRUCache is an in-memory Cache holding up to a fixed number of entries; the
+// least recently used entry is evicted to make room for a new one.
+type LRUCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+// NewLRUCache returns an LRUCache holding up to size entries.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    max(size, 1),
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// lruItem is the value of the list elements of an LRUCache.
+type lruItem struct {
+	key   string
+	entry CacheEntry
+}
+
+func (c *LRUCache) Get(key string) (entry CacheEntry, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return entry, false
+	}
+	entry = element.Value.(lruItem).entry
+	if !c.now().Before(entry.ExpiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return CacheEntry{}, false
+	}
+	c.order.MoveToFront(element)
+	return entry, true
+}
+
+func (c *LRUCache) Set(key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = lruItem{key: key, entry: entry}
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(lruItem{key: key, entry: entry})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(lruItem).key)
+	}
+}
+
+// Len returns the number of entries held, expired ones included.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,80 @@
+package mail_checker
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestCacheEntry(email string, expiresAt time.Time) CacheEntry {
+	return CacheEntry{
+		Email:     email,
+		Kind:      MailKindYahoo,
+		Status:    getStatusById(StatusIdLive),
+		CheckedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// Test the LRU cache evicts the least recently used entry
+func TestLRUCacheEviction(t *testing.T) {
+	cache := NewLRUCache(2)
+	expiresAt := time.Now().Add(time.Hour)
+	cache.Set("a@yahoo.com", newTestCacheEntry("a@yahoo.com", expiresAt))
+	cache.Set("b@yahoo.com", newTestCacheEntry("b@yahoo.com", expiresAt))
+	if _, ok := cache.Get("a@yahoo.com"); !ok {
+		t.Fatalf("expected a@yahoo.com to be cached")
+	}
+	cache.Set("c@yahoo.com", newTestCacheEntry("c@yahoo.com", expiresAt))
+
+	if _, ok := cache.Get("b@yahoo.com"); ok {
+		t.Fatalf("expected b@yahoo.com to be evicted")
+	}
+	for _, email := range []string{"a@yahoo.com", "c@yahoo.com"} {
+		if entry, ok := cache.Get(email); !ok || entry.Email != email || entry.Kind != MailKindYahoo {
+			t.Fatalf("expected %s to be cached, got %+v", email, entry)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", cache.Len())
+	}
+}
+
+// Test the LRU cache drops expired entries
+func TestLRUCacheExpiry(t *testing.T) {
+	cache := NewLRUCache(2)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	cache.Set("a@yahoo.com", newTestCacheEntry("a@yahoo.com", now.Add(time.Minute)))
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("a@yahoo.com"); ok {
+		t.Fatalf("expected the entry to be expired")
+	}
+	if cache.Len() != 0 {
+		t.Fatalf("expected the expired entry to be removed, got %d entries", cache.Len())
+	}
+}
+
+// Test the LRU cache under concurrent use
+func TestLRUCacheConcurrent(t *testing.T) {
+	cache := NewLRUCache(50)
+	expiresAt := time.Now().Add(time.Hour)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				email := fmt.Sprintf("user%d@yahoo.com", (w*200+i)%100)
+				cache.Set(email, newTestCacheEntry(email, expiresAt))
+				cache.Get(email)
+			}
+		}(w)
+	}
+	wg.Wait()
+	if cache.Len() != 50 {
+		t.Fatalf("expected the cache to be full with 50 entries, got %d", cache.Len())
+	}
+}
@@ -2,9 +2,9 @@ package mail_checker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	log "github.com/sirupsen/logrus"
 	"io"
 	"net/http"
 	"regexp"
@@ -12,56 +12,107 @@ import (
 )
 
 type microsoftMail struct {
-	client *http.Client
+	client    *http.Client
+	limiter   *RateLimiter
+	session   *sessionCache[microsoftSession]
+	endpoints *Endpoints
+	logger    Logger
+	timeouts  phaseTimeouts
+}
+
+func newMicrosoftMail(client *http.Client) *microsoftMail {
+	return &microsoftMail{
+		client:  client,
+		limiter: limiterFor(MailKindMicrosoft),
+		session: newSessionCache[microsoftSession](DefaultSessionPolicy),
+	}
+}
+
+func (h *microsoftMail) apply(o options) *microsoftMail {
+	h.limiter = o.rateLimiter(MailKindMicrosoft)
+	h.session = newSessionCache[microsoftSession](o.sessionPolicy())
+	h.endpoints = o.endpoints
+	h.logger = o.logger
+	h.timeouts = o.phaseTimeouts()
+	return h
 }
 
 func (h *microsoftMail) Check(email string) (status Status) {
-	err, amscCookie, canary := h.getAmscAndCanaryCookie()
+	return h.CheckContext(context.Background(), email)
+}
+
+func (h *microsoftMail) CheckContext(ctx context.Context, email string) (status Status) {
+	return h.CheckDetail(ctx, email).Status
+}
+
+func (h *microsoftMail) CheckDetail(ctx context.Context, email string) (result CheckResult) {
+	result = newCheckResult(MailKindMicrosoft, email)
+	local, _, err := result.normalize()
+	if err == nil {
+		err = checkLocalPartRule(MailKindMicrosoft, email, local)
+	}
+	logger := checkLogger(h.logger, MailKindMicrosoft, result.Email)
 	if err != nil {
-		log.Errorf("[MicrosoftMail] - [Check] - %s", err.Error())
-		return getStatusById(StatusIdCheckError)
+		logger.Debug("Invalid address", logKeyPhase, logPhaseValidate, logKeyError, redactError(err))
+		return result.finish(StatusIdFormatInvalid, "", err)
 	}
 
+	return runPhases(ctx, result, logger, h.session, h.getSession, h.checkAvailable, h.timeouts)
+}
+
+// checkAvailable probes the address with the session. The second value
+// reports that the upstream rejected the session.
+func (h *microsoftMail) checkAvailable(ctx context.Context, result CheckResult, session microsoftSession) (CheckResult, bool) {
 	var bodyReq = map[string]interface{}{
-		"signInName":         email,
+		"signInName":         result.Email,
 		"includeSuggestions": true,
 	}
 	var body, _ = json.Marshal(bodyReq)
+	logger := checkLogger(h.logger, MailKindMicrosoft, result.Email)
 
-	r, err := http.NewRequest(http.MethodPost, hotmailUrlCheckAvailable, bytes.NewBuffer(body))
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, h.endpoints.probeOr(hotmailUrlCheckAvailable), bytes.NewBuffer(body))
 	if err != nil {
-		return getStatusById(StatusIdCheckError)
+		return result.finish(StatusIdCheckError, "", err), false
 	}
-	r.Header.Set("canary", canary)
+	r.Header.Set("canary", session.Canary)
 	r.Header.Set("content-type", "application/json")
-	r.Header.Set("cookie", `amsc=`+amscCookie+`;`)
+	r.Header.Set("cookie", `amsc=`+session.Amsc+`;`)
+	if err = h.limiter.Wait(ctx); err != nil {
+		return result.finish(StatusIdCheckError, "", err), false
+	}
 	res, err := h.client.Do(r)
-	h.client.CloseIdleConnections()
 
 	if err != nil {
-		log.Errorf("Exec request: %+v", err)
-		return getStatusById(StatusIdCheckError)
+		logger.Error("Exec request failed", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
 	}
 
-	defer res.Body.Close()
+	defer closeBody(res.Body)
+	if isSessionRejectedStatus(res) {
+		return result.finish(StatusIdCheckError, "", newHttpStatusError(res)), true
+	}
+	if err = checkHttpStatus(res); err != nil {
+		logger.Error("Unexpected response", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
+	}
 	bodyText, _ := io.ReadAll(res.Body)
 	jsonString := string(bodyText)
 	if !strings.Contains(jsonString, `isAvailable`) {
-		log.Errorf("[MicrosoftMail] - [Check] - The isAvailable field does not exsist in the response")
-		return getStatusById(StatusIdCheckError)
+		logger.Warn("The isAvailable field does not exist in the response", logKeyPhase, logPhaseProbe)
+		return result.finish(StatusIdCheckError, "", ErrMicrosoftIsAvailableMissing), true
 	}
 
 	var checkerResponse microsoftMailResResGetEmailAvailable
 	err = json.Unmarshal(bodyText, &checkerResponse)
 	if err != nil {
-		log.Errorf("[MicrosoftMail] - [Check] - Parser JsonBody error: %+v", err)
-		return getStatusById(StatusIdCheckError)
+		logger.Error("Parse response failed", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
 	}
 
 	if checkerResponse.IsAvailable {
-		return getStatusById(StatusIdNotExists)
+		return result.finish(StatusIdNotExists, hotmailReasonAvailable, nil), false
 	}
-	return getStatusById(StatusIdLive)
+	return result.finish(StatusIdLive, hotmailReasonNotAvailable, nil), false
 }
 
 func (h *microsoftMail) getAmscCookie(res *http.Response) (err error, amscCookie string) {
@@ -96,17 +147,22 @@ func (h *microsoftMail) getCanaryCookie(html string) (err error, canary string)
 	return nil, dataBody.ApiCanary
 }
 
-func (h *microsoftMail) getAmscAndCanaryCookie() (err error, amscCookie string, canary string) {
-	r, err := http.NewRequest(http.MethodGet, hotmailUrlSignup, nil)
+func (h *microsoftMail) getAmscAndCanaryCookie(ctx context.Context) (err error, amscCookie string, canary string) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, h.endpoints.bootstrapOr(hotmailUrlSignup), nil)
 	if err != nil {
 		return err, amscCookie, amscCookie
 	}
+	if err = h.limiter.Wait(ctx); err != nil {
+		return err, amscCookie, canary
+	}
 	res, err := h.client.Do(r)
-	h.client.CloseIdleConnections()
 	if err != nil {
 		return err, amscCookie, canary
 	}
-	defer res.Body.Close()
+	defer closeBody(res.Body)
+	if err = checkHttpStatus(res); err != nil {
+		return err, amscCookie, canary
+	}
 
 	err, amscCookie = h.getAmscCookie(res)
 	if err != nil {
@@ -120,3 +176,8 @@ func (h *microsoftMail) getAmscAndCanaryCookie() (err error, amscCookie string,
 	}
 	return err, amscCookie, canary
 }
+
+func (h *microsoftMail) getSession(ctx context.Context) (session microsoftSession, err error) {
+	err, session.Amsc, session.Canary = h.getAmscAndCanaryCookie(ctx)
+	return session, err
+}
@@ -1,6 +1,7 @@
 package mail_checker
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -96,7 +97,7 @@ func TestGetAmscAndCanaryCookie_Success(t *testing.T) {
 	}
 
 	mailChecker := &microsoftMail{client: client}
-	err, amscCookie, canary := mailChecker.getAmscAndCanaryCookie()
+	err, amscCookie, canary := mailChecker.getAmscAndCanaryCookie(context.Background())
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -119,7 +120,7 @@ func TestGetAmscAndCanaryCookie_HttpRequestError(t *testing.T) {
 	}
 
 	mailChecker := &microsoftMail{client: client}
-	err, _, _ := mailChecker.getAmscAndCanaryCookie()
+	err, _, _ := mailChecker.getAmscAndCanaryCookie(context.Background())
 	if err == nil {
 		t.Fatalf("expected HTTP request error, got nil")
 	}
@@ -234,3 +235,153 @@ func TestCheck_MalformedJsonResponse(t *testing.T) {
 		t.Fatalf("expected StatusIdCheckError, got %v", status.Id)
 	}
 }
+
+// Test the CheckContext function passes the context to every request
+func TestCheckContext_PropagatesContext(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "marker")
+	var seen int
+	client := &http.Client{
+		Transport: &mockTransport{
+			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Context().Value(ctxKey{}) != "marker" {
+					return nil, errors.New("context was not propagated")
+				}
+				seen++
+				if req.URL.String() == hotmailUrlSignup {
+					return &http.Response{
+						StatusCode: 200,
+						Header: http.Header{
+							"Set-Cookie": {"amsc=testCookie; path=/;"},
+						},
+						Body: io.NopCloser(strings.NewReader(`var ServerData={"apiCanary":"testCanary"};`)),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"isAvailable":false}`)),
+				}, nil
+			},
+		},
+	}
+
+	mailChecker := &microsoftMail{client: client}
+	status := mailChecker.CheckContext(ctx, "test@example.com")
+	if status.Id != StatusIdLive {
+		t.Fatalf("expected StatusIdLive, got %v", status.Id)
+	}
+	if seen != 2 {
+		t.Fatalf("expected 2 requests with the caller context, got %d", seen)
+	}
+}
+
+// Test the CheckContext function for a cancelled context
+func TestCheckContext_Cancelled(t *testing.T) {
+	client := &http.Client{
+		Transport: &mockTransport{
+			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+				return nil, req.Context().Err()
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mailChecker := &microsoftMail{client: client}
+	status := mailChecker.CheckContext(ctx, "test@example.com")
+	if status.Id != StatusIdCheckError {
+		t.Fatalf("expected StatusIdCheckError, got %v", status.Id)
+	}
+}
+
+// Test the CheckDetail function reports the reason and timing of the verdict
+func TestCheckDetail_Result(t *testing.T) {
+	client := &http.Client{
+		Transport: &mockTransport{
+			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+				if req.URL.String() == hotmailUrlSignup {
+					return &http.Response{
+						StatusCode: 200,
+						Header: http.Header{
+							"Set-Cookie": {"amsc=testCookie; path=/;"},
+						},
+						Body: io.NopCloser(strings.NewReader(`var ServerData={"apiCanary":"testCanary"};`)),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(`{"isAvailable":false}`)),
+				}, nil
+			},
+		},
+	}
+
+	mailChecker := &microsoftMail{client: client}
+	result := mailChecker.CheckDetail(context.Background(), " test@example.com ")
+	if result.Status.Id != StatusIdLive {
+		t.Fatalf("expected StatusIdLive, got %v", result.Status.Id)
+	}
+	if result.Email != "test@example.com" {
+		t.Fatalf("expected normalized email, got %q", result.Email)
+	}
+	if result.Kind != MailKindMicrosoft {
+		t.Fatalf("expected MailKindMicrosoft, got %v", result.Kind)
+	}
+	if result.Reason != hotmailReasonNotAvailable {
+		t.Fatalf("expected reason %q, got %q", hotmailReasonNotAvailable, result.Reason)
+	}
+	if result.Err != nil {
+		t.Fatalf("expected no error, got %v", result.Err)
+	}
+	if result.FinishedAt.Before(result.StartedAt) || result.Latency != result.FinishedAt.Sub(result.StartedAt) {
+		t.Fatalf("unexpected timing: %+v", result)
+	}
+}
+
+// Test the CheckDetail function keeps the underlying error
+func TestCheckDetail_Error(t *testing.T) {
+	client := &http.Client{
+		Transport: &mockTransport{
+			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader("")),
+				}, nil
+			},
+		},
+	}
+
+	mailChecker := &microsoftMail{client: client}
+	result := mailChecker.CheckDetail(context.Background(), "test@example.com")
+	if result.Status.Id != StatusIdCheckError {
+		t.Fatalf("expected StatusIdCheckError, got %v", result.Status.Id)
+	}
+	if !errors.Is(result.Err, ErrMicrosoftGetAmscCookieError) {
+		t.Fatalf("expected ErrMicrosoftGetAmscCookieError, got %v", result.Err)
+	}
+}
+
+// Test the Check function rejects a malformed email without any request
+func TestCheck_FormatInvalid(t *testing.T) {
+	client := &http.Client{
+		Transport: &mockTransport{
+			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+				t.Errorf("unexpected request to %s", req.URL)
+				return nil, errors.New("unexpected request")
+			},
+		},
+	}
+
+	mailChecker := &microsoftMail{client: client}
+	for _, email := range []string{"a@@b.com", `"@x"`, "invalid-email-format"} {
+		result := mailChecker.CheckDetail(context.Background(), email)
+		if result.Status.Id != StatusIdFormatInvalid {
+			t.Fatalf("%s: expected StatusIdFormatInvalid, got %v", email, result.Status.Id)
+		}
+		var addrErr *AddressError
+		if !errors.As(result.Err, &addrErr) {
+			t.Fatalf("%s: expected AddressError, got %v", email, result.Err)
+		}
+	}
+}
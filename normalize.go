@@ -0,0 +1,56 @@
+package mail_checker
+
+import (
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+)
+
+// NormalizeAddress returns the canonical form of an email address: the local
+// part in Unicode NFC and the domain in lower-case ASCII, with
+// internationalized labels converted to punycode. The local part is also
+// lower-cased for the hosted providers, which treat it case-insensitively;
+// other domains keep its case because RFC 5321 lets their servers decide.
+func NormalizeAddress(email string) (string, error) {
+	local, domain, err := normalizeAddress(email)
+	if err != nil {
+		return "", err
+	}
+	return local + "@" + domain, nil
+}
+
+func normalizeAddress(email string) (local string, domain string, err error) {
+	input := strings.TrimSpace(email)
+	local, domain, err = SplitAddress(input)
+	if err != nil {
+		return "", "", err
+	}
+
+	local = norm.NFC.String(local)
+	if !strings.HasPrefix(domain, "[") {
+		ascii, err := idna.Lookup.ToASCII(domain)
+		if err != nil {
+			return "", "", &AddressError{Address: input, Reason: "invalid internationalized domain: " + err.Error()}
+		}
+		domain = strings.ToLower(ascii)
+	}
+	if _, known := DetectMailKind("@" + domain); known && !strings.HasPrefix(local, `"`) {
+		local = strings.ToLower(local)
+	}
+
+	// Punycode can make the domain longer, so check the limits again.
+	normalized := local + "@" + domain
+	if _, _, err = SplitAddress(normalized); err != nil {
+		return "", "", &AddressError{Address: input, Reason: err.(*AddressError).Reason}
+	}
+	return local, domain, nil
+}
+
+func (r *CheckResult) normalize() (local string, domain string, err error) {
+	local, domain, err = normalizeAddress(r.Input)
+	if err != nil {
+		return "", "", err
+	}
+	r.Email = local + "@" + domain
+	return local, domain, nil
+}
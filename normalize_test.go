@@ -0,0 +1,72 @@
+package mail_checker
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// Test NormalizeAddress converts internationalized addresses
+func TestNormalizeAddress(t *testing.T) {
+	cases := map[string]string{
+		" User@Example.COM ":           "User@example.com",
+		"Jörg@Bücher.de":               "Jörg@xn--bcher-kva.de",
+		"nguyễn@ví-dụ.vn":              "nguyễn@xn--v-d-rma6749a.vn",
+		"Someone@Hotmail.com":          "someone@hotmail.com",
+		"Someone@GMAIL.com":            "someone@gmail.com",
+		`"Quoted"@Yahoo.com`:           `"Quoted"@yahoo.com`,
+		"user@[IPv6:2001:DB8::1]":      "user@[IPv6:2001:DB8::1]",
+		"user@xn--mnchen-3ya.de":       "user@xn--mnchen-3ya.de",
+		"jo\u0308rg@example.com":       "j\u00f6rg@example.com", // decomposed ö becomes NFC
+		"straße@faß.de":                "straße@xn--fa-hia.de",
+		"ÜBER@MÜNCHEN.DE":              "ÜBER@xn--mnchen-3ya.de",
+		"first.last@outlook.com.vn":    "first.last@outlook.com.vn",
+		"MiXeD.CaSe@SubDomain.Corp.io": "MiXeD.CaSe@subdomain.corp.io",
+	}
+	for input, want := range cases {
+		got, err := NormalizeAddress(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%q: expected %q, got %q", input, want, got)
+		}
+	}
+}
+
+// Test NormalizeAddress rejects domains that are not valid IDNA
+func TestNormalizeAddress_Invalid(t *testing.T) {
+	for _, input := range []string{"user@xn--a.com", "user@\u0300abc.com", "a@@b.com"} {
+		_, err := NormalizeAddress(input)
+		var addrErr *AddressError
+		if !errors.As(err, &addrErr) {
+			t.Errorf("%q: expected an AddressError, got %v", input, err)
+		}
+	}
+}
+
+// Test the result keeps the original input next to the normalized email
+func TestCheckResultNormalize(t *testing.T) {
+	router := &routerMail{
+		checkers: map[MailKind]Checker{
+			MailKindGoogle: &stubChecker{kind: MailKindGoogle},
+		},
+	}
+	result := router.CheckDetail(context.Background(), "Người.Dùng@GMAIL.com")
+	if result.Status.Id != StatusIdLive {
+		t.Fatalf("expected StatusIdLive, got %+v", result)
+	}
+
+	result = newCheckResult(MailKindGoogle, " Jörg@Bücher.de ")
+	local, domain, err := result.normalize()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if result.Input != " Jörg@Bücher.de " || result.Email != "Jörg@xn--bcher-kva.de" {
+		t.Fatalf("unexpected input/email %q %q", result.Input, result.Email)
+	}
+	if local != "Jörg" || domain != "xn--bcher-kva.de" {
+		t.Fatalf("unexpected split %q %q", local, domain)
+	}
+}
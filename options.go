@@ -0,0 +1,260 @@
+package mail_checker
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// NewWithOptions returns a checker of the kind configured by the options. It
+// fails for an unknown kind or options that do not apply to it.
+func NewWithOptions(mailKind MailKind, opts ...Option) (Checker, error) {
+	o := options{timeout: httpClientTimeoutDefault}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.validate(mailKind); err != nil {
+		return nil, err
+	}
+
+	checker, err := o.newChecker(mailKind)
+	if err != nil || o.cache == nil {
+		return checker, err
+	}
+	return NewCachedChecker(checker, o.cache.cache, o.cache.policy), nil
+}
+
+// newChecker builds the checker of the kind, without the cache.
+func (o options) newChecker(mailKind MailKind) (Checker, error) {
+	var client *http.Client
+	if mailKind != MailKindSmtp {
+		var err error
+		if client, err = o.httpClient(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch mailKind {
+	case MailKindMicrosoft:
+		return newMicrosoftMail(client).apply(o), nil
+	case MailKindYahoo:
+		return newYahooMail(client).apply(o), nil
+	case MailKindGoogle:
+		return newGoogleMail(client).apply(o), nil
+	case MailKindSmtp:
+		checker := newSmtpMail()
+		dial := o.dial
+		if dial.timeout == 0 {
+			dial.timeout = smtpTimeoutDefault
+		}
+		dialer := newDialer(dial)
+		checker.lookupMX = dialer.resolver().LookupMX
+		checker.dial = dialer.DialContext
+		checker.timeout = o.timeout
+		checker.logger = o.logger
+		checker.limiter = o.rateLimiter(MailKindSmtp)
+		return checker, nil
+	case MailKindAuto:
+		return &routerMail{
+			checkers: map[MailKind]Checker{
+				MailKindMicrosoft: newMicrosoftMail(client).apply(o),
+				MailKindYahoo:     newYahooMail(client).apply(o),
+				MailKindGoogle:    newGoogleMail(client).apply(o),
+			},
+			logger: o.logger,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrInvalidMailKind, mailKind)
+}
+
+// WithHTTPClient makes the checker send its requests with the client instead
+// of one built for it. It cannot be combined with WithProxy.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithTimeout sets the time limit of every upstream request, or of every SMTP
+// conversation. The default is 5 seconds. WithBootstrapTimeout and
+// WithProbeTimeout override it for their phase.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithProxy sends the upstream requests through the proxy.
+func WithProxy(proxy Proxy) Option {
+	return func(o *options) {
+		o.proxy = proxy
+	}
+}
+
+// WithProxyFromEnvironment sends the upstream requests through the proxy set
+// by the HTTPS_PROXY or, failing that, ALL_PROXY environment variable, except
+// for the hosts listed in NO_PROXY. The lower case variables are read too. A
+// proxy given with WithProxy takes precedence.
+//
+// Without it, the checkers still use the HTTP and HTTPS proxies of
+// HTTPS_PROXY and NO_PROXY, like net/http, but not the SOCKS5 ones nor
+// ALL_PROXY, and an invalid variable only fails the requests.
+func WithProxyFromEnvironment() Option {
+	return func(o *options) {
+		o.proxyFromEnv = true
+	}
+}
+
+// WithLogger sets the logger of the checker. The default is NopLogger; use
+// NewSlogLogger or NewLogrusLogger to log to slog or logrus.
+func WithLogger(logger Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the upstream requests.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithEndpoints replaces the URLs of the provider's signup page and
+// availability endpoint, for example to reach it through a gateway. It only
+// applies to the microsoft, yahoo and google kinds. An empty URL keeps the
+// provider's own.
+func WithEndpoints(endpoints Endpoints) Option {
+	return func(o *options) {
+		o.endpoints = &endpoints
+	}
+}
+
+func (o options) validate(mailKind MailKind) error {
+	switch mailKind {
+	case MailKindMicrosoft, MailKindYahoo, MailKindGoogle, MailKindSmtp, MailKindAuto:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidMailKind, mailKind)
+	}
+	if o.client != nil && (o.proxy.Host != "" || o.proxyFromEnv) {
+		return fmt.Errorf("%w: WithProxy cannot be combined with WithHTTPClient", ErrInvalidOption)
+	}
+	if o.rateLimit != nil && o.limiter != nil {
+		return fmt.Errorf("%w: WithRateLimit cannot be combined with WithRateLimiter", ErrInvalidOption)
+	}
+	if o.client != nil && o.transport != nil {
+		return fmt.Errorf("%w: WithTransportPolicy cannot be combined with WithHTTPClient", ErrInvalidOption)
+	}
+	if o.transport != nil && mailKind == MailKindSmtp {
+		return fmt.Errorf("%w: WithTransportPolicy does not apply to the %q kind", ErrInvalidOption, mailKind)
+	}
+	if o.cache != nil && o.cache.cache == nil {
+		return fmt.Errorf("%w: WithCache needs a cache", ErrInvalidOption)
+	}
+	if o.client != nil && o.tls.isSet() {
+		return fmt.Errorf("%w: the TLS options cannot be combined with WithHTTPClient", ErrInvalidOption)
+	}
+	if v := o.tls.minVersion; v != 0 && (v < tls.VersionTLS10 || v > tls.VersionTLS13) {
+		return fmt.Errorf("%w: unknown TLS version %#x", ErrInvalidOption, v)
+	}
+	if err := o.dial.validate(); err != nil {
+		return err
+	}
+	if o.timeout < 0 {
+		return fmt.Errorf("%w: negative timeout %v", ErrInvalidOption, o.timeout)
+	}
+	if o.timeouts.bootstrap < 0 || o.timeouts.probe < 0 || o.timeouts.check < 0 {
+		return fmt.Errorf("%w: negative phase timeout", ErrInvalidOption)
+	}
+	if (o.proxy.Host != "" || o.proxyFromEnv) && mailKind == MailKindSmtp {
+		return fmt.Errorf("%w: the proxy does not apply to the %q kind", ErrInvalidOption, mailKind)
+	}
+	if o.tls.isSet() && mailKind == MailKindSmtp {
+		return fmt.Errorf("%w: the TLS options do not apply to the %q kind", ErrInvalidOption, mailKind)
+	}
+	if o.session != nil && (o.session.TTL < 0 || o.session.MaxUses < 0) {
+		return fmt.Errorf("%w: negative session TTL or uses", ErrInvalidOption)
+	}
+	if o.session != nil && mailKind == MailKindSmtp {
+		return fmt.Errorf("%w: WithSessionPolicy does not apply to the %q kind", ErrInvalidOption, mailKind)
+	}
+	if o.timeouts != (phaseTimeouts{}) && mailKind == MailKindSmtp {
+		return fmt.Errorf("%w: the phase timeouts do not apply to the %q kind", ErrInvalidOption, mailKind)
+	}
+	if o.endpoints != nil {
+		switch mailKind {
+		case MailKindMicrosoft, MailKindYahoo, MailKindGoogle:
+		default:
+			return fmt.Errorf("%w: WithEndpoints does not apply to the %q kind", ErrInvalidOption, mailKind)
+		}
+	}
+	return nil
+}
+
+func (o options) httpClient() (*http.Client, error) {
+	var client http.Client
+	if o.client != nil {
+		client = *o.client
+	} else {
+		proxy := o.proxy
+		if proxy.Host == "" && o.proxyFromEnv {
+			var err error
+			if proxy, err = proxyFromEnvironment(); err != nil {
+				return nil, err
+			}
+		}
+		made, err := makeHttpClient(proxy, o.dial, o.transportPolicy())
+		if err != nil {
+			return nil, err
+		}
+		if made.Transport.(*http.Transport).TLSClientConfig, err = o.tls.config(); err != nil {
+			return nil, err
+		}
+		client = *made
+		timeouts := o.phaseTimeouts()
+		client.Timeout = max(o.timeout, timeouts.bootstrap, timeouts.probe)
+	}
+	if o.userAgent != "" {
+		transport := client.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		client.Transport = &userAgentTransport{transport: transport, userAgent: o.userAgent}
+	}
+	return &client, nil
+}
+
+// userAgentTransport sets the User-Agent header of the requests without one.
+type userAgentTransport struct {
+	transport http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.transport.RoundTrip(req)
+}
+
+func (t *userAgentTransport) CloseIdleConnections() {
+	if closer, ok := t.transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (e *Endpoints) bootstrapOr(fallback string) string {
+	if e == nil || e.Bootstrap == "" {
+		return fallback
+	}
+	return e.Bootstrap
+}
+
+func (e *Endpoints) probeOr(fallback string) string {
+	if e == nil || e.Probe == "" {
+		return fallback
+	}
+	return e.Probe
+}
@@ -0,0 +1,126 @@
+package mail_checker
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Test NewWithOptions rejects an unknown kind and conflicting options
+func TestNewWithOptionsErrors(t *testing.T) {
+	if checker, err := NewWithOptions("aol"); !errors.Is(err, ErrInvalidMailKind) || checker != nil {
+		t.Errorf("expected ErrInvalidMailKind, got %v and %v", checker, err)
+	}
+	invalid := map[string][]Option{
+		"proxy with client":   {WithHTTPClient(http.DefaultClient), WithProxy(Proxy{Host: "127.0.0.1:8080"})},
+		"negative timeout":    {WithTimeout(-time.Second)},
+		"endpoints with auto": {WithEndpoints(Endpoints{Probe: "http://127.0.0.1/"})},
+	}
+	for name, opts := range invalid {
+		if _, err := NewWithOptions(MailKindAuto, opts...); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", name, err)
+		}
+	}
+	smtpInvalid := map[string][]Option{
+		"proxy":                  {WithProxy(Proxy{Host: "127.0.0.1:8080"})},
+		"proxy from environment": {WithProxyFromEnvironment()},
+		"minimum TLS version":    {WithMinTLSVersion(tls.VersionTLS12)},
+		"root CAs":               {WithRootCAs(x509.NewCertPool())},
+	}
+	for name, opts := range smtpInvalid {
+		if _, err := NewWithOptions(MailKindSmtp, opts...); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%s with smtp: expected ErrInvalidOption, got %v", name, err)
+		}
+	}
+	if New(MailKindSmtp, Proxy{Host: "127.0.0.1:8080"}) != nil {
+		t.Errorf("expected New to return nil for the smtp kind with a proxy")
+	}
+	if New("aol", Proxy{}) != nil {
+		t.Errorf("expected New to return nil for an unknown kind")
+	}
+}
+
+// Test WithTimeout sets the limit of the HTTP client and of SMTP conversations
+func TestNewWithOptionsTimeout(t *testing.T) {
+	checker, err := NewWithOptions(MailKindYahoo, WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeout := checker.(*yahooMail).client.Timeout; timeout != time.Second {
+		t.Errorf("expected a 1s client timeout, got %v", timeout)
+	}
+
+	checker, err = NewWithOptions(MailKindSmtp, WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeout := checker.(*smtpMail).timeout; timeout != time.Second {
+		t.Errorf("expected a 1s SMTP timeout, got %v", timeout)
+	}
+}
+
+// Test the endpoints, user agent and HTTP client options reach the requests
+func TestNewWithOptionsEndpoints(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		switch r.URL.Path {
+		case "/signup":
+			w.Header().Set("Set-Cookie", "amsc=testCookie; path=/;")
+			io.WriteString(w, `var ServerData={"apiCanary":"testCanary"};`)
+		case "/check":
+			io.WriteString(w, `{"isAvailable":true}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	checker, err := NewWithOptions(MailKindMicrosoft,
+		WithHTTPClient(server.Client()),
+		WithUserAgent("mail-checker-test/1.0"),
+		WithEndpoints(Endpoints{Bootstrap: server.URL + "/signup", Probe: server.URL + "/check"}),
+		WithRateLimit(0, 0),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if status := checker.Check("test@hotmail.com"); status.Id != StatusIdNotExists {
+		t.Fatalf("expected StatusIdNotExists, got %v", status.Id)
+	}
+	if len(userAgents) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(userAgents))
+	}
+	for _, userAgent := range userAgents {
+		if userAgent != "mail-checker-test/1.0" {
+			t.Errorf("expected the configured user agent, got %q", userAgent)
+		}
+	}
+}
+
+// Test WithLogger routes the checker's logs
+func TestNewWithOptionsLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetLevel(logrus.DebugLevel)
+
+	checker, err := NewWithOptions(MailKindAuto, WithLogger(NewLogrusLogger(logger)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checker.Check("someone@example.org")
+	if !strings.Contains(buf.String(), "Unsupported provider") {
+		t.Errorf("expected the log to go to the logger, got %q", buf.String())
+	}
+}
@@ -0,0 +1,134 @@
+package mail_checker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	rateLimitersMu    sync.Mutex
+	rateLimiters      = make(map[MailKind]*RateLimiter)
+	rateLimitDefaults = map[MailKind]rateLimit{
+		MailKindMicrosoft: {rate: rateLimitRateDefault, burst: rateLimitBurstDefault},
+		MailKindYahoo:     {rate: rateLimitRateDefault, burst: rateLimitBurstDefault},
+		MailKindGoogle:    {rate: rateLimitRateDefault, burst: rateLimitBurstDefault},
+	}
+)
+
+// RateLimiter is a token bucket: it holds up to burst tokens and refills at
+// rate tokens per second. A zero rate disables the limit. Share one between
+// checkers with WithRateLimiter.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// SetRateLimit sets how many upstream requests per second, with bursts of up
+// to burst requests, the checkers of the kind may send in this process, all
+// together. It is the default of the checkers built without WithRateLimit or
+// WithRateLimiter. A rate of zero or less removes the limit.
+func SetRateLimit(kind MailKind, requestsPerSecond float64, burst int) {
+	limiterFor(kind).setLimit(requestsPerSecond, burst)
+}
+
+// NewRateLimiter returns a limiter letting requestsPerSecond requests through,
+// with bursts of up to burst requests. A rate of zero or less disables it.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	limiter := &RateLimiter{}
+	limiter.setLimit(requestsPerSecond, burst)
+	return limiter
+}
+
+// WithRateLimit gives the checker its own limit of requests per second, with
+// bursts of up to burst requests, instead of the limit of its kind that
+// SetRateLimit sets. Each provider of the auto kind gets its own. A rate of
+// zero or less removes the limit.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(o *options) {
+		o.rateLimit = &rateLimit{rate: requestsPerSecond, burst: burst}
+	}
+}
+
+// WithRateLimiter makes the checker wait for the limiter, which other
+// checkers, of any kind, may share.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(o *options) {
+		o.limiter = limiter
+	}
+}
+
+// rateLimiter returns the limiter of a checker of the kind built with the
+// options.
+func (o options) rateLimiter(kind MailKind) *RateLimiter {
+	switch {
+	case o.limiter != nil:
+		return o.limiter
+	case o.rateLimit != nil:
+		return NewRateLimiter(o.rateLimit.rate, o.rateLimit.burst)
+	}
+	return limiterFor(kind)
+}
+
+func limiterFor(kind MailKind) *RateLimiter {
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+
+	limiter, ok := rateLimiters[kind]
+	if !ok {
+		limiter = &RateLimiter{}
+		if limit, ok := rateLimitDefaults[kind]; ok {
+			limiter.setLimit(limit.rate, limit.burst)
+		}
+		rateLimiters[kind] = limiter
+	}
+	return limiter
+}
+
+func (l *RateLimiter) setLimit(rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rate = max(rate, 0)
+	l.burst = float64(max(burst, 1))
+	l.tokens = l.burst
+	l.last = time.Now()
+}
+
+// Wait blocks until a request may be sent or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	if l.rate == 0 {
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reserved token back for the next caller.
+		l.mu.Lock()
+		l.tokens = min(l.burst, l.tokens+1)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
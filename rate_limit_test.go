@@ -0,0 +1,105 @@
+package mail_checker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test the limiter lets a burst through and then spaces requests out
+func TestRateLimiterWait(t *testing.T) {
+	limiter := &RateLimiter{}
+	limiter.setLimit(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	// 2 requests from the burst, 4 more at 10ms each.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Fatalf("expected the limiter to wait about 40ms, waited %v", elapsed)
+	}
+}
+
+// Test the limiter stops waiting when the context is done
+func TestRateLimiterWait_Cancelled(t *testing.T) {
+	limiter := &RateLimiter{}
+	limiter.setLimit(0.1, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("expected the first request to pass, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected Wait to return with the context, waited %v", elapsed)
+	}
+}
+
+// Test a disabled or missing limiter never waits
+func TestRateLimiterWait_Disabled(t *testing.T) {
+	var missing *RateLimiter
+	if err := missing.Wait(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	limiter := &RateLimiter{}
+	limiter.setLimit(0, 1)
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		_ = limiter.Wait(context.Background())
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("expected no wait, waited %v", elapsed)
+	}
+}
+
+// Test checkers of the same kind share one limiter that SetRateLimit updates
+func TestSetRateLimit(t *testing.T) {
+	first := New(MailKindYahoo, Proxy{}).(*yahooMail)
+	second := New(MailKindYahoo, Proxy{Host: "127.0.0.1:8080"}).(*yahooMail)
+	if first.limiter == nil || first.limiter != second.limiter {
+		t.Fatalf("expected checkers of the same kind to share a limiter")
+	}
+	if New(MailKindMicrosoft, Proxy{}).(*microsoftMail).limiter == first.limiter {
+		t.Fatalf("expected each kind to have its own limiter")
+	}
+
+	SetRateLimit(MailKindYahoo, 42, 3)
+	defer SetRateLimit(MailKindYahoo, rateLimitRateDefault, rateLimitBurstDefault)
+	if first.limiter.rate != 42 || first.limiter.burst != 3 {
+		t.Fatalf("expected the shared limiter to be updated, got %v/%v", first.limiter.rate, first.limiter.burst)
+	}
+}
+
+// Test WithRateLimit gives each checker its own limiter and WithRateLimiter
+// shares one between checkers of any kind
+func TestWithRateLimit(t *testing.T) {
+	shared := limiterFor(MailKindYahoo)
+	first, _ := NewWithOptions(MailKindYahoo, WithRateLimit(42, 3))
+	second, _ := NewWithOptions(MailKindYahoo, WithRateLimit(42, 3))
+	own := first.(*yahooMail).limiter
+	if own == shared || own == second.(*yahooMail).limiter || own.rate != 42 || own.burst != 3 {
+		t.Fatalf("expected each checker to get its own 42/3 limiter")
+	}
+
+	limiter := NewRateLimiter(10, 2)
+	yahoo, _ := NewWithOptions(MailKindYahoo, WithRateLimiter(limiter))
+	auto, _ := NewWithOptions(MailKindAuto, WithRateLimiter(limiter))
+	smtp, _ := NewWithOptions(MailKindSmtp, WithRateLimiter(limiter))
+	if yahoo.(*yahooMail).limiter != limiter || smtp.(*smtpMail).limiter != limiter ||
+		auto.(*routerMail).checkers[MailKindGoogle].(*googleMail).limiter != limiter {
+		t.Fatalf("expected the checkers to share the limiter")
+	}
+
+	if _, err := NewWithOptions(MailKindYahoo, WithRateLimit(1, 1), WithRateLimiter(limiter)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
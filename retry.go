@@ -0,0 +1,130 @@
+package mail_checker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+var transientErrors = []error{
+	io.EOF,
+	io.ErrUnexpectedEOF,
+	syscall.ECONNRESET,
+	syscall.ECONNREFUSED,
+	syscall.ECONNABORTED,
+	syscall.EPIPE,
+	context.DeadlineExceeded,
+	ErrTimeout,
+	ErrSessionTokenMissing,
+}
+
+type retryChecker struct {
+	checker Checker
+	policy  RetryPolicy
+}
+
+// NewRetryChecker wraps the checker so that checks failing with a transient
+// error are retried with exponential backoff and jitter. Zero fields of the
+// policy take the defaults: 3 attempts, 500ms base delay, 5s maximum delay.
+func NewRetryChecker(checker Checker, policy RetryPolicy) Checker {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = retryMaxAttemptsDefault
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = retryBaseDelayDefault
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = retryMaxDelayDefault
+	}
+	return &retryChecker{
+		checker: checker,
+		policy:  policy,
+	}
+}
+
+// IsTransient reports whether a check failing with err may succeed if tried
+// again: timeouts, dropped connections, throttling and server errors, and
+// session tokens missing from the upstream page. Invalid addresses,
+// cancellation and definitive answers are permanent.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var addrErr *AddressError
+	if errors.As(err, &addrErr) {
+		return false
+	}
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) {
+		if _, isNetErr := temporary.(net.Error); !isNetErr {
+			return temporary.Temporary()
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	for _, transient := range transientErrors {
+		if errors.Is(err, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *retryChecker) Check(email string) (status Status) {
+	return c.CheckContext(context.Background(), email)
+}
+
+func (c *retryChecker) CheckContext(ctx context.Context, email string) (status Status) {
+	return c.CheckDetail(ctx, email).Status
+}
+
+func (c *retryChecker) CheckDetail(ctx context.Context, email string) (result CheckResult) {
+	startedAt := time.Now()
+	for attempt := 1; ; attempt++ {
+		result = c.checker.CheckDetail(ctx, email)
+		result.Attempts = attempt
+		if result.Status.Id != StatusIdCheckError || !IsTransient(result.Err) || attempt >= c.policy.MaxAttempts {
+			break
+		}
+		delay := c.backoff(attempt)
+		var rateLimitErr *RateLimitError
+		if errors.As(result.Err, &rateLimitErr) && rateLimitErr.RetryAfter > delay {
+			delay = min(rateLimitErr.RetryAfter, c.policy.MaxDelay)
+		}
+		if !c.sleep(ctx, delay) {
+			break
+		}
+	}
+	result.StartedAt = startedAt
+	result.Latency = result.FinishedAt.Sub(startedAt)
+	return result
+}
+
+// backoff returns the delay before the next attempt: the base delay doubled
+// for every attempt made, capped, then jittered between half and all of it.
+func (c *retryChecker) backoff(attempt int) time.Duration {
+	delay := c.policy.BaseDelay
+	for i := 1; i < attempt && delay < c.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, c.policy.MaxDelay)
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func (c *retryChecker) sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,184 @@
+package mail_checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// Test IsTransient classifies failures
+func TestIsTransient(t *testing.T) {
+	transient := []error{
+		timeoutError{},
+		&net.OpError{Op: "read", Err: syscall.ECONNRESET},
+		fmt.Errorf("read body: %w", io.ErrUnexpectedEOF),
+		&HttpStatusError{StatusCode: http.StatusTooManyRequests},
+		&HttpStatusError{StatusCode: http.StatusBadGateway},
+		&SmtpReplyError{Code: 451, Msg: "greylisted"},
+		fmt.Errorf("failed to get canary cookie: %w", ErrMicrosoftGetCanaryCookieError),
+		ErrYahooGetCookieError,
+		fmt.Errorf("could not detect value for crumb: %w", ErrYahooGetSessionValue),
+		ErrGoogleGetXsrfTokenError,
+		context.DeadlineExceeded,
+	}
+	for _, err := range transient {
+		if !IsTransient(err) {
+			t.Errorf("%v: expected transient", err)
+		}
+	}
+
+	permanent := []error{
+		nil,
+		&AddressError{Address: "a@@b", Reason: "bad"},
+		&HttpStatusError{StatusCode: http.StatusForbidden},
+		&SmtpReplyError{Code: 554, Msg: "rejected"},
+		context.Canceled,
+		ErrUnsupportedProvider,
+		errors.New("something else"),
+	}
+	for _, err := range permanent {
+		if IsTransient(err) {
+			t.Errorf("%v: expected permanent", err)
+		}
+	}
+}
+
+// Test the retry checker retries transient failures only
+func TestRetryChecker(t *testing.T) {
+	var calls int
+	flaky := &errorChecker{results: func(attempt int) (StatusId, error) {
+		calls++
+		if attempt < 3 {
+			return StatusIdCheckError, &HttpStatusError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return StatusIdLive, nil
+	}}
+	checker := NewRetryChecker(flaky, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond})
+
+	result := checker.CheckDetail(context.Background(), "test@example.com")
+	if result.Status.Id != StatusIdLive || result.Attempts != 3 || calls != 3 {
+		t.Fatalf("expected live after 3 attempts, got %+v (%d calls)", result, calls)
+	}
+	if result.Latency != result.FinishedAt.Sub(result.StartedAt) || result.Latency < time.Millisecond {
+		t.Fatalf("expected the latency to cover every attempt, got %v", result.Latency)
+	}
+
+	// Permanent failures are not retried
+	calls = 0
+	flaky.results = func(attempt int) (StatusId, error) {
+		calls++
+		return StatusIdCheckError, errors.New("permanent")
+	}
+	result = checker.CheckDetail(context.Background(), "test@example.com")
+	if result.Attempts != 1 || calls != 1 {
+		t.Fatalf("expected a single attempt, got %d (%d calls)", result.Attempts, calls)
+	}
+
+	// Definitive answers are not retried
+	calls = 0
+	flaky.results = func(attempt int) (StatusId, error) {
+		calls++
+		return StatusIdNotExists, nil
+	}
+	if status := checker.Check("test@example.com"); status.Id != StatusIdNotExists || calls != 1 {
+		t.Fatalf("expected a single not exists answer, got %v (%d calls)", status.Id, calls)
+	}
+
+	// Attempts stop at MaxAttempts
+	calls = 0
+	flaky.results = func(attempt int) (StatusId, error) {
+		calls++
+		return StatusIdCheckError, timeoutError{}
+	}
+	result = checker.CheckDetail(context.Background(), "test@example.com")
+	if result.Status.Id != StatusIdCheckError || result.Attempts != 5 || calls != 5 {
+		t.Fatalf("expected 5 attempts, got %d (%d calls)", result.Attempts, calls)
+	}
+}
+
+// Test the retry checker stops waiting when the context is done
+func TestRetryChecker_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	flaky := &errorChecker{results: func(attempt int) (StatusId, error) {
+		cancel()
+		return StatusIdCheckError, timeoutError{}
+	}}
+	checker := NewRetryChecker(flaky, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour})
+
+	start := time.Now()
+	result := checker.CheckDetail(ctx, "test@example.com")
+	if result.Attempts != 1 || time.Since(start) > time.Second {
+		t.Fatalf("expected the retry to stop with the context, got %+v", result)
+	}
+}
+
+// Test the backoff grows exponentially within the bounds
+func TestRetryBackoff(t *testing.T) {
+	c := NewRetryChecker(nil, RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}).(*retryChecker)
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		for i := 0; i < 20; i++ {
+			if delay := c.backoff(attempt); delay < want/2 || delay > want {
+				t.Fatalf("attempt %d: expected a delay in [%v, %v], got %v", attempt, want/2, want, delay)
+			}
+		}
+	}
+	if c.policy.MaxAttempts != retryMaxAttemptsDefault {
+		t.Fatalf("expected the default max attempts, got %d", c.policy.MaxAttempts)
+	}
+}
+
+// Test providers report 429 and 5xx responses as transient errors
+func TestRetryProviderStatus(t *testing.T) {
+	client := newMockClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	})
+	for _, checker := range []Checker{&microsoftMail{client: client}, &yahooMail{client: client}, &googleMail{client: client}} {
+		result := checker.CheckDetail(context.Background(), "testuser@example.com")
+		var statusErr *HttpStatusError
+		if !errors.As(result.Err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("%T: expected a 429 HttpStatusError, got %v", checker, result.Err)
+		}
+		if !IsTransient(result.Err) {
+			t.Fatalf("%T: expected a transient error", checker)
+		}
+	}
+}
+
+// errorChecker returns the status and error chosen for each attempt
+type errorChecker struct {
+	attempt int
+	results func(attempt int) (StatusId, error)
+}
+
+func (c *errorChecker) Check(email string) (status Status) {
+	return c.CheckContext(context.Background(), email)
+}
+
+func (c *errorChecker) CheckContext(ctx context.Context, email string) (status Status) {
+	return c.CheckDetail(ctx, email).Status
+}
+
+func (c *errorChecker) CheckDetail(ctx context.Context, email string) (result CheckResult) {
+	result = newCheckResult(MailKindSmtp, email)
+	c.attempt++
+	id, err := c.results(c.attempt)
+	return result.finish(id, "", err)
+}
@@ -0,0 +1,82 @@
+package mail_checker
+
+import (
+	"context"
+	"golang.org/x/net/publicsuffix"
+	"strings"
+)
+
+var (
+	// routerDomainPrefixes are the providers' own names, followed by a public
+	// suffix in their domains: hotmail.com, hotmail.co.uk, yahoo.fr...
+	routerDomainPrefixes = map[string]MailKind{
+		"hotmail": MailKindMicrosoft,
+		"outlook": MailKindMicrosoft,
+		"live":    MailKindMicrosoft,
+		"yahoo":   MailKindYahoo,
+	}
+	routerDomains = map[string]MailKind{
+		"msn.com":        MailKindMicrosoft,
+		"ymail.com":      MailKindYahoo,
+		"rocketmail.com": MailKindYahoo,
+		"gmail.com":      MailKindGoogle,
+		"googlemail.com": MailKindGoogle,
+	}
+)
+
+type routerMail struct {
+	checkers map[MailKind]Checker
+	logger   Logger
+}
+
+// DetectMailKind returns the provider that hosts the email's domain. Only
+// the providers' consumer domains match, not the subdomains of other
+// domains like live.company.com.
+func DetectMailKind(email string) (kind MailKind, ok bool) {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return kind, false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+
+	if kind, ok = routerDomains[domain]; ok {
+		return kind, true
+	}
+	label, rest, found := strings.Cut(domain, ".")
+	if !found {
+		return kind, false
+	}
+	if kind, ok = routerDomainPrefixes[label]; !ok {
+		return kind, false
+	}
+	if suffix, icann := publicsuffix.PublicSuffix(domain); !icann || suffix != rest {
+		return "", false
+	}
+	return kind, true
+}
+
+func (r *routerMail) Check(email string) (status Status) {
+	return r.CheckContext(context.Background(), email)
+}
+
+func (r *routerMail) CheckContext(ctx context.Context, email string) (status Status) {
+	return r.CheckDetail(ctx, email).Status
+}
+
+func (r *routerMail) CheckDetail(ctx context.Context, email string) (result CheckResult) {
+	result = newCheckResult(MailKindAuto, email)
+	_, _, err := result.normalize()
+	logger := checkLogger(r.logger, MailKindAuto, result.Email)
+	if err != nil {
+		logger.Debug("Invalid address", logKeyPhase, logPhaseValidate, logKeyError, redactError(err))
+		return result.finish(StatusIdFormatInvalid, "", err)
+	}
+
+	kind, ok := DetectMailKind(result.Email)
+	checker := r.checkers[kind]
+	if !ok || checker == nil {
+		logger.Debug("Unsupported provider", logKeyPhase, logPhaseRoute)
+		return result.finish(StatusIdUnsupported, "", ErrUnsupportedProvider)
+	}
+	return checker.CheckDetail(ctx, email)
+}
@@ -0,0 +1,105 @@
+package mail_checker
+
+import (
+	"context"
+	"testing"
+)
+
+type stubChecker struct {
+	kind      MailKind
+	checkFunc func(ctx context.Context, email string) StatusId
+}
+
+func (s *stubChecker) Check(email string) (status Status) {
+	return s.CheckContext(context.Background(), email)
+}
+
+func (s *stubChecker) CheckContext(ctx context.Context, email string) (status Status) {
+	return s.CheckDetail(ctx, email).Status
+}
+
+func (s *stubChecker) CheckDetail(ctx context.Context, email string) (result CheckResult) {
+	result = newCheckResult(s.kind, email)
+	id := StatusIdLive
+	if s.checkFunc != nil {
+		id = s.checkFunc(ctx, email)
+	}
+	return result.finish(id, "", nil)
+}
+
+// Test DetectMailKind for the supported provider domains
+func TestDetectMailKind(t *testing.T) {
+	cases := map[string]MailKind{
+		"a@hotmail.com":      MailKindMicrosoft,
+		"a@hotmail.co.uk":    MailKindMicrosoft,
+		"a@Outlook.com":      MailKindMicrosoft,
+		"a@outlook.com.vn":   MailKindMicrosoft,
+		"a@live.fr":          MailKindMicrosoft,
+		"a@msn.com":          MailKindMicrosoft,
+		"a@yahoo.com":        MailKindYahoo,
+		"a@yahoo.co.jp":      MailKindYahoo,
+		"a@ymail.com":        MailKindYahoo,
+		"a@rocketmail.com":   MailKindYahoo,
+		"a@gmail.com":        MailKindGoogle,
+		"a@googlemail.com":   MailKindGoogle,
+		" a@GMAIL.COM ":      MailKindGoogle,
+		"first.last@live.it": MailKindMicrosoft,
+	}
+	for email, want := range cases {
+		kind, ok := DetectMailKind(email)
+		if !ok || kind != want {
+			t.Errorf("%s: expected %v, got %v (%v)", email, want, kind, ok)
+		}
+	}
+
+	unsupported := []string{
+		"a@example.com", "a@hotmail", "a@msn.org", "no-at-sign", "a@mygmail.com",
+		"a@live.company.com", "a@outlook.example.org", "a@yahoo.evil.net", "a@hotmail.blogspot.com", "a@gmail.com.",
+	}
+	for _, email := range unsupported {
+		if kind, ok := DetectMailKind(email); ok {
+			t.Errorf("%s: expected unsupported, got %v", email, kind)
+		}
+	}
+}
+
+// Test the router dispatches each email to the checker of its provider
+func TestRouterCheck(t *testing.T) {
+	router := &routerMail{
+		checkers: map[MailKind]Checker{
+			MailKindMicrosoft: &stubChecker{kind: MailKindMicrosoft},
+			MailKindYahoo: &stubChecker{kind: MailKindYahoo, checkFunc: func(ctx context.Context, email string) StatusId {
+				return StatusIdNotExists
+			}},
+			MailKindGoogle: &stubChecker{kind: MailKindGoogle},
+		},
+	}
+
+	result := router.CheckDetail(context.Background(), "test@outlook.com")
+	if result.Kind != MailKindMicrosoft || result.Status.Id != StatusIdLive {
+		t.Fatalf("expected live microsoft result, got %+v", result)
+	}
+
+	result = router.CheckDetail(context.Background(), "test@ymail.com")
+	if result.Kind != MailKindYahoo || result.Status.Id != StatusIdNotExists {
+		t.Fatalf("expected not exists yahoo result, got %+v", result)
+	}
+
+	status := router.Check("test@gmail.com")
+	if status.Id != StatusIdLive {
+		t.Fatalf("expected StatusIdLive, got %v", status.Id)
+	}
+
+	result = router.CheckDetail(context.Background(), "a@@hotmail.com")
+	if result.Status.Id != StatusIdFormatInvalid {
+		t.Fatalf("expected StatusIdFormatInvalid, got %+v", result.Status)
+	}
+
+	result = router.CheckDetail(context.Background(), "test@example.com")
+	if result.Status.Id != StatusIdUnsupported || result.Status.Name != StatusNameUnsupported {
+		t.Fatalf("expected StatusIdUnsupported, got %+v", result.Status)
+	}
+	if result.Err != ErrUnsupportedProvider {
+		t.Fatalf("expected ErrUnsupportedProvider, got %v", result.Err)
+	}
+}
@@ -0,0 +1,142 @@
+package mail_checker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultSessionPolicy is the session policy of the checkers built without
+// WithSessionPolicy.
+var DefaultSessionPolicy = SessionPolicy{
+	TTL:     sessionTTLDefault,
+	MaxUses: sessionMaxUsesDefault,
+}
+
+// WithSessionPolicy sets when the checker refreshes the provider session,
+// instead of DefaultSessionPolicy. It only applies to the microsoft, yahoo
+// and google kinds, and to each provider of the auto kind.
+func WithSessionPolicy(policy SessionPolicy) Option {
+	return func(o *options) {
+		o.session = &policy
+	}
+}
+
+// sessionPolicy returns the session policy of a checker built with the
+// options.
+func (o options) sessionPolicy() SessionPolicy {
+	if o.session != nil {
+		return *o.session
+	}
+	return DefaultSessionPolicy
+}
+
+// sessionCache keeps the session material scraped from a provider's signup
+// page so that it is reused across checks. Concurrent callers needing a new
+// session share a single fetch.
+type sessionCache[T any] struct {
+	policy SessionPolicy
+
+	mu         sync.Mutex
+	value      T
+	valid      bool
+	generation int
+	fetchedAt  time.Time
+	uses       int
+	refresh    *sessionRefresh[T]
+}
+
+type sessionRefresh[T any] struct {
+	done       chan struct{}
+	value      T
+	generation int
+	err        error
+}
+
+func newSessionCache[T any](policy SessionPolicy) *sessionCache[T] {
+	return &sessionCache[T]{policy: policy}
+}
+
+// get returns the cached session, or fetches a new one when there is none or
+// it is expired. The generation identifies the session for invalidate.
+func (c *sessionCache[T]) get(ctx context.Context, fetch func(ctx context.Context) (T, error)) (value T, generation int, err error) {
+	if c == nil {
+		value, err = fetch(ctx)
+		return value, generation, err
+	}
+
+	for {
+		c.mu.Lock()
+		if c.valid && !c.expired() {
+			c.uses++
+			value, generation = c.value, c.generation
+			c.mu.Unlock()
+			return value, generation, nil
+		}
+
+		refresh := c.refresh
+		if refresh == nil {
+			refresh = &sessionRefresh[T]{done: make(chan struct{})}
+			c.refresh = refresh
+			c.mu.Unlock()
+
+			refresh.value, refresh.err = fetch(ctx)
+
+			c.mu.Lock()
+			c.refresh = nil
+			if refresh.err == nil {
+				c.generation++
+				c.value, c.valid = refresh.value, true
+				c.fetchedAt, c.uses = time.Now(), 1
+				refresh.generation = c.generation
+			}
+			c.mu.Unlock()
+			close(refresh.done)
+			return refresh.value, refresh.generation, refresh.err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-refresh.done:
+		case <-ctx.Done():
+			return value, generation, ctx.Err()
+		}
+		// The caller that fetched gave up on its own context: fetch again.
+		if errors.Is(refresh.err, context.Canceled) || errors.Is(refresh.err, context.DeadlineExceeded) {
+			continue
+		}
+		if refresh.err != nil {
+			return value, generation, refresh.err
+		}
+		c.mu.Lock()
+		c.uses++
+		c.mu.Unlock()
+		return refresh.value, refresh.generation, nil
+	}
+}
+
+// invalidate drops the session of the given generation after the upstream
+// rejected it. A newer session fetched meanwhile is kept.
+func (c *sessionCache[T]) invalidate(generation int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.valid = false
+	}
+}
+
+func (c *sessionCache[T]) expired() bool {
+	if c.policy.TTL > 0 && time.Since(c.fetchedAt) >= c.policy.TTL {
+		return true
+	}
+	return c.policy.MaxUses > 0 && c.uses >= c.policy.MaxUses
+}
+
+func isSessionRejectedStatus(res *http.Response) bool {
+	return res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden
+}
@@ -0,0 +1,231 @@
+package mail_checker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test the session cache reuses a session until its use count runs out
+func TestSessionCache_MaxUses(t *testing.T) {
+	cache := newSessionCache[int](SessionPolicy{MaxUses: 3})
+	var fetches int
+	fetch := func(ctx context.Context) (int, error) {
+		fetches++
+		return fetches, nil
+	}
+
+	for i, want := range []int{1, 1, 1, 2, 2, 2, 3} {
+		value, _, err := cache.get(context.Background(), fetch)
+		if err != nil || value != want {
+			t.Fatalf("get %d: expected session %d, got %d (%v)", i, want, value, err)
+		}
+	}
+}
+
+// Test the session cache refetches an expired session
+func TestSessionCache_TTL(t *testing.T) {
+	cache := newSessionCache[int](SessionPolicy{TTL: 20 * time.Millisecond})
+	var fetches int
+	fetch := func(ctx context.Context) (int, error) {
+		fetches++
+		return fetches, nil
+	}
+
+	cache.get(context.Background(), fetch)
+	cache.get(context.Background(), fetch)
+	if fetches != 1 {
+		t.Fatalf("expected 1 fetch, got %d", fetches)
+	}
+	time.Sleep(25 * time.Millisecond)
+	if value, _, _ := cache.get(context.Background(), fetch); value != 2 {
+		t.Fatalf("expected a new session after the TTL, got %d", value)
+	}
+}
+
+// Test concurrent callers share a single fetch
+func TestSessionCache_SingleFlight(t *testing.T) {
+	cache := newSessionCache[string](SessionPolicy{})
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (string, error) {
+		fetches.Add(1)
+		<-release
+		return "session", nil
+	}
+
+	var wg sync.WaitGroup
+	values := make([]string, 20)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], _, _ = cache.get(context.Background(), fetch)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if fetches.Load() != 1 {
+		t.Fatalf("expected a single fetch, got %d", fetches.Load())
+	}
+	for i, value := range values {
+		if value != "session" {
+			t.Fatalf("caller %d: expected the shared session, got %q", i, value)
+		}
+	}
+}
+
+// Test a caller waiting on a fetch whose owner gave up fetches again
+func TestSessionCache_OwnerCancelled(t *testing.T) {
+	cache := newSessionCache[string](SessionPolicy{})
+	started := make(chan struct{})
+	var fetches atomic.Int32
+	fetch := func(ctx context.Context) (string, error) {
+		if fetches.Add(1) == 1 {
+			close(started)
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "fresh", nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go cache.get(ctx, fetch)
+	<-started
+
+	done := make(chan string)
+	go func() {
+		value, _, _ := cache.get(context.Background(), fetch)
+		done <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if value := <-done; value != "fresh" {
+		t.Fatalf("expected the waiting caller to fetch a fresh session, got %q", value)
+	}
+}
+
+// Test invalidate drops only the rejected generation
+func TestSessionCache_Invalidate(t *testing.T) {
+	cache := newSessionCache[int](SessionPolicy{})
+	var fetches int
+	fetch := func(ctx context.Context) (int, error) {
+		fetches++
+		if fetches == 2 {
+			return 0, errors.New("fetch failed")
+		}
+		return fetches, nil
+	}
+
+	_, generation, _ := cache.get(context.Background(), fetch)
+	cache.invalidate(generation)
+	if _, _, err := cache.get(context.Background(), fetch); err == nil {
+		t.Fatalf("expected the fetch error")
+	}
+	value, newer, _ := cache.get(context.Background(), fetch)
+	if value != 3 {
+		t.Fatalf("expected session 3, got %d", value)
+	}
+
+	cache.invalidate(generation)
+	if value, _, _ = cache.get(context.Background(), fetch); value != 3 {
+		t.Fatalf("expected an old invalidation to keep the newer session, got %d", value)
+	}
+	cache.invalidate(newer)
+	if value, _, _ = cache.get(context.Background(), fetch); value != 4 {
+		t.Fatalf("expected a new session, got %d", value)
+	}
+}
+
+// Test the Microsoft checker reuses its session and refreshes it when rejected
+func TestSessionReuseMicrosoft(t *testing.T) {
+	var signups, probes atomic.Int32
+	canary := "canary1"
+	client := newMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() == hotmailUrlSignup {
+			n := signups.Add(1)
+			if n > 1 {
+				canary = "canary2"
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Set-Cookie": {"amsc=testCookie; path=/;"}},
+				Body:       io.NopCloser(strings.NewReader(`var ServerData={"apiCanary":"` + canary + `"};`)),
+			}, nil
+		}
+		probes.Add(1)
+		if req.Header.Get("canary") == "stale" {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"code":"6001"}}`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"isAvailable":false}`)),
+		}, nil
+	})
+	built, err := NewWithOptions(MailKindMicrosoft, WithHTTPClient(client), WithRateLimit(0, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checker := built.(*microsoftMail)
+
+	for i := 0; i < 5; i++ {
+		if status := checker.Check("test@hotmail.com"); status.Id != StatusIdLive {
+			t.Fatalf("check %d: expected StatusIdLive, got %v", i, status.Id)
+		}
+	}
+	if signups.Load() != 1 || probes.Load() != 5 {
+		t.Fatalf("expected 1 signup fetch and 5 probes, got %d and %d", signups.Load(), probes.Load())
+	}
+
+	// The upstream rejects the cached canary: the checker refreshes it once.
+	checker.session.mu.Lock()
+	checker.session.value.Canary = "stale"
+	checker.session.mu.Unlock()
+	if status := checker.Check("test@hotmail.com"); status.Id != StatusIdLive {
+		t.Fatalf("expected StatusIdLive after the refresh, got %v", status.Id)
+	}
+	if signups.Load() != 2 || probes.Load() != 7 {
+		t.Fatalf("expected 2 signup fetches and 7 probes, got %d and %d", signups.Load(), probes.Load())
+	}
+}
+
+// Test WithSessionPolicy sets the policy of each provider instead of the
+// default one
+func TestWithSessionPolicy(t *testing.T) {
+	policy := SessionPolicy{TTL: time.Minute, MaxUses: 3}
+	checker, err := NewWithOptions(MailKindAuto, WithSessionPolicy(policy))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	router := checker.(*routerMail)
+	if router.checkers[MailKindMicrosoft].(*microsoftMail).session.policy != policy ||
+		router.checkers[MailKindYahoo].(*yahooMail).session.policy != policy ||
+		router.checkers[MailKindGoogle].(*googleMail).session.policy != policy {
+		t.Errorf("expected every provider to use the policy")
+	}
+	if New(MailKindGoogle, Proxy{}).(*googleMail).session.policy != DefaultSessionPolicy {
+		t.Errorf("expected DefaultSessionPolicy without the option")
+	}
+
+	invalid := map[MailKind]SessionPolicy{
+		MailKindYahoo: {TTL: -time.Minute},
+		MailKindSmtp:  policy,
+	}
+	for kind, policy := range invalid {
+		if _, err := NewWithOptions(kind, WithSessionPolicy(policy)); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", kind, err)
+		}
+	}
+}
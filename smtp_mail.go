@@ -0,0 +1,199 @@
+package mail_checker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+type smtpMail struct {
+	lookupMX func(ctx context.Context, domain string) ([]*net.MX, error)
+	dial     func(ctx context.Context, network, address string) (net.Conn, error)
+	limiter  *RateLimiter
+	port     string
+	heloName string
+	mailFrom string
+	timeout  time.Duration
+	logger   Logger
+}
+
+func newSmtpMail() *smtpMail {
+	dialer := &net.Dialer{Timeout: smtpTimeoutDefault}
+	return &smtpMail{
+		lookupMX: net.DefaultResolver.LookupMX,
+		dial:     dialer.DialContext,
+		limiter:  limiterFor(MailKindSmtp),
+		port:     smtpPortDefault,
+		heloName: smtpHeloNameDefault,
+		timeout:  smtpTimeoutDefault,
+	}
+}
+
+func (s *smtpMail) Check(email string) (status Status) {
+	return s.CheckContext(context.Background(), email)
+}
+
+func (s *smtpMail) CheckContext(ctx context.Context, email string) (status Status) {
+	return s.CheckDetail(ctx, email).Status
+}
+
+func (s *smtpMail) CheckDetail(ctx context.Context, email string) (result CheckResult) {
+	result = newCheckResult(MailKindSmtp, email)
+	_, domain, err := result.normalize()
+	logger := checkLogger(s.logger, MailKindSmtp, result.Email)
+	if err != nil {
+		logger.Debug("Invalid address", logKeyPhase, logPhaseValidate, logKeyError, redactError(err))
+		return result.finish(StatusIdFormatInvalid, "", err)
+	}
+
+	hosts, err := s.getMailHosts(ctx, domain)
+	if err != nil {
+		logger.Error("Lookup MX failed", logKeyPhase, logPhaseLookup, "domain", domain, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err)
+	}
+
+	client, err := s.connect(ctx, hosts)
+	if err != nil {
+		logger.Error("Connect failed", logKeyPhase, logPhaseConnect, "domain", domain, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err)
+	}
+	defer client.Close()
+
+	if err = client.Hello(s.heloName); err != nil {
+		return s.finishReply(ctx, result, err, false)
+	}
+	if err = client.Mail(s.mailFrom); err != nil {
+		return s.finishReply(ctx, result, err, false)
+	}
+	if err = client.Rcpt(result.Email); err != nil {
+		return s.finishReply(ctx, result, err, true)
+	}
+
+	// The mailbox was accepted; a random mailbox on the same domain being
+	// accepted as well means the server accepts everything.
+	probe := s.randomLocalPart() + "@" + domain
+	if err = client.Rcpt(probe); err == nil {
+		_ = client.Quit()
+		return result.finish(StatusIdCatchAll, "250", nil)
+	}
+	_ = client.Quit()
+	return result.finish(StatusIdLive, "250", nil)
+}
+
+// finishReply turns a failed command into the result. Only the reply to the
+// recipient says something about the mailbox; a refused EHLO or sender is a
+// failure of the check.
+func (s *smtpMail) finishReply(ctx context.Context, result CheckResult, err error, recipient bool) CheckResult {
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		checkLogger(s.logger, MailKindSmtp, result.Email).Error("Conversation failed", logKeyPhase, logPhaseConverse, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err)
+	}
+
+	reason := fmt.Sprintf("%d %s", protoErr.Code, protoErr.Msg)
+	if !recipient {
+		return result.finish(StatusIdCheckError, reason, &SmtpReplyError{Code: protoErr.Code, Msg: protoErr.Msg})
+	}
+	switch protoErr.Code {
+	case 550, 551, 553:
+		return result.finish(StatusIdNotExists, reason, nil)
+	case 452, 552:
+		return result.finish(StatusIdMailboxFull, reason, nil)
+	}
+	return result.finish(StatusIdCheckError, reason, &SmtpReplyError{Code: protoErr.Code, Msg: protoErr.Msg})
+}
+
+func (s *smtpMail) getMailHosts(ctx context.Context, domain string) ([]string, error) {
+	if strings.HasPrefix(domain, "[") && strings.HasSuffix(domain, "]") {
+		literal := strings.TrimPrefix(domain[1:len(domain)-1], "IPv6:")
+		return []string{literal}, nil
+	}
+
+	records, err := s.lookupMX(ctx, domain)
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		return nil, err
+	}
+
+	hosts := make([]string, 0, len(records))
+	for _, mx := range records {
+		host := strings.TrimSuffix(mx.Host, ".")
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		// No MX record: the domain itself is the implicit mail host (RFC 5321, 5.1).
+		hosts = append(hosts, domain)
+	}
+	return hosts, nil
+}
+
+func (s *smtpMail) connect(ctx context.Context, hosts []string) (*smtp.Client, error) {
+	var lastErr error = ErrSmtpNoMailHost
+	for _, host := range hosts {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		conn, err := s.dial(ctx, dialProtocol, net.JoinHostPort(host, s.port))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		deadline := time.Now().Add(s.timeout)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		_ = conn.SetDeadline(deadline)
+		stop := context.AfterFunc(ctx, func() {
+			_ = conn.SetDeadline(time.Now())
+		})
+
+		client, err := smtp.NewClient(&smtpConn{Conn: conn, stop: stop}, host)
+		if err != nil {
+			stop()
+			conn.Close()
+			// A permanent refusal is final; a busy host (421) leaves the
+			// next one to try.
+			var protoErr *textproto.Error
+			if errors.As(err, &protoErr) {
+				err = &SmtpReplyError{Code: protoErr.Code, Msg: protoErr.Msg}
+				if protoErr.Code >= 500 {
+					return nil, err
+				}
+			}
+			lastErr = err
+			continue
+		}
+		return client, nil
+	}
+	return nil, lastErr
+}
+
+func (s *smtpMail) randomLocalPart() string {
+	b := make([]byte, smtpProbeLocalPartLen/2)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// smtpConn releases the context watcher when the SMTP client closes the connection.
+type smtpConn struct {
+	net.Conn
+	stop func() bool
+}
+
+func (c *smtpConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
@@ -0,0 +1,268 @@
+package mail_checker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// smtpStandIn is a minimal SMTP server on a loopback port answering RCPT TO
+// with the reply configured for the mailbox, or the default reply, and MAIL
+// FROM with mailReply, 250 when unset.
+type smtpStandIn struct {
+	listener     net.Listener
+	mailReply    string
+	replies      map[string]string
+	defaultReply string
+	greeting     string
+	sawData      atomic.Bool
+}
+
+func newSmtpStandIn(t *testing.T, greeting string, replies map[string]string, defaultReply string) *smtpStandIn {
+	return newSmtpStandInAt(t, "127.0.0.1:0", greeting, replies, defaultReply)
+}
+
+func newSmtpStandInAt(t *testing.T, address string, greeting string, replies map[string]string, defaultReply string) *smtpStandIn {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{
+		listener:     listener,
+		replies:      replies,
+		defaultReply: defaultReply,
+		greeting:     greeting,
+	}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	write := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	write(s.greeting)
+	if !strings.HasPrefix(s.greeting, "220") {
+		return
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			write("250 stand-in")
+		case "MAIL":
+			if s.mailReply != "" {
+				write(s.mailReply)
+			} else {
+				write("250 2.1.0 OK")
+			}
+		case "RCPT":
+			addr := strings.Trim(strings.TrimPrefix(line[len("RCPT TO:"):], " "), "<>")
+			local := strings.SplitN(addr, "@", 2)[0]
+			if reply, ok := s.replies[local]; ok {
+				write(reply)
+			} else {
+				write(s.defaultReply)
+			}
+		case "DATA":
+			s.sawData.Store(true)
+			write("554 no data expected")
+		case "QUIT":
+			write("221 bye")
+			return
+		default:
+			write("502 not implemented")
+		}
+	}
+}
+
+func (s *smtpStandIn) checker() *smtpMail {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	checker := newSmtpMail()
+	checker.port = port
+	checker.lookupMX = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		return []*net.MX{{Host: "127.0.0.1.", Pref: 10}}, nil
+	}
+	return checker
+}
+
+// Test the Check function maps RCPT TO replies to statuses
+func TestSmtpCheck(t *testing.T) {
+	standIn := newSmtpStandIn(t, "220 stand-in ESMTP", map[string]string{
+		"live":     "250 2.1.5 OK",
+		"unknown":  "550 5.1.1 user unknown",
+		"relay":    "551 user not local",
+		"bad":      "553 mailbox name not allowed",
+		"full":     "452 4.2.2 mailbox full",
+		"quota":    "552 5.2.2 over quota",
+		"greylist": "450 4.7.1 greylisted, try again later",
+	}, "550 5.1.1 user unknown")
+	checker := standIn.checker()
+
+	cases := map[string]StatusId{
+		"live@example.com":    StatusIdLive,
+		"unknown@example.com": StatusIdNotExists,
+		"relay@example.com":   StatusIdNotExists,
+		"bad@example.com":     StatusIdNotExists,
+		"full@example.com":    StatusIdMailboxFull,
+		"quota@example.com":   StatusIdMailboxFull,
+	}
+	for email, want := range cases {
+		result := checker.CheckDetail(context.Background(), email)
+		if result.Status.Id != want {
+			t.Errorf("%s: expected %v, got %v (%v)", email, want, result.Status.Id, result.Err)
+		}
+		if result.Kind != MailKindSmtp {
+			t.Errorf("%s: expected MailKindSmtp, got %v", email, result.Kind)
+		}
+	}
+
+	result := checker.CheckDetail(context.Background(), "unknown@example.com")
+	if result.Reason != "550 5.1.1 user unknown" {
+		t.Errorf("expected the reply as reason, got %q", result.Reason)
+	}
+	if standIn.sawData.Load() {
+		t.Errorf("expected no DATA command to be sent")
+	}
+}
+
+// Test greylisting replies come back as a temporary error
+func TestSmtpCheck_Greylisted(t *testing.T) {
+	standIn := newSmtpStandIn(t, "220 stand-in ESMTP", nil, "450 4.7.1 greylisted, try again later")
+	checker := standIn.checker()
+
+	result := checker.CheckDetail(context.Background(), "someone@example.com")
+	if result.Status.Id != StatusIdCheckError {
+		t.Fatalf("expected StatusIdCheckError, got %v", result.Status.Id)
+	}
+	var replyErr *SmtpReplyError
+	if !errors.As(result.Err, &replyErr) {
+		t.Fatalf("expected SmtpReplyError, got %v", result.Err)
+	}
+	if replyErr.Code != 450 || !replyErr.Temporary() {
+		t.Fatalf("expected a temporary 450 reply, got %+v", replyErr)
+	}
+}
+
+// Test a refused sender fails the check instead of condemning the mailbox
+func TestSmtpCheck_SenderRejected(t *testing.T) {
+	for _, reply := range []string{"550 5.7.1 sender rejected", "451 4.3.0 try again later"} {
+		standIn := newSmtpStandIn(t, "220 stand-in ESMTP", map[string]string{"live": "250 2.1.5 OK"}, "550 5.1.1 user unknown")
+		standIn.mailReply = reply
+		checker := standIn.checker()
+
+		result := checker.CheckDetail(context.Background(), "live@example.com")
+		var replyErr *SmtpReplyError
+		if result.Status.Id != StatusIdCheckError || !errors.As(result.Err, &replyErr) {
+			t.Fatalf("%s: expected StatusIdCheckError with SmtpReplyError, got %v (%v)", reply, result.Status.Id, result.Err)
+		}
+		if temporary := strings.HasPrefix(reply, "4"); replyErr.Temporary() != temporary {
+			t.Errorf("%s: expected Temporary %v, got %+v", reply, temporary, replyErr)
+		}
+	}
+}
+
+// Test a domain accepting a random mailbox is reported as catch-all
+func TestSmtpCheck_CatchAll(t *testing.T) {
+	standIn := newSmtpStandIn(t, "220 stand-in ESMTP", nil, "250 2.1.5 OK")
+	checker := standIn.checker()
+
+	status := checker.Check("anyone@example.com")
+	if status.Id != StatusIdCatchAll {
+		t.Fatalf("expected StatusIdCatchAll, got %v", status.Id)
+	}
+}
+
+// Test a rejected greeting is reported as a reply error
+func TestSmtpCheck_GreetingRejected(t *testing.T) {
+	standIn := newSmtpStandIn(t, "421 4.3.2 service not available", nil, "250 OK")
+	checker := standIn.checker()
+
+	result := checker.CheckDetail(context.Background(), "someone@example.com")
+	var replyErr *SmtpReplyError
+	if result.Status.Id != StatusIdCheckError || !errors.As(result.Err, &replyErr) || !replyErr.Temporary() {
+		t.Fatalf("expected a temporary reply error, got %+v", result)
+	}
+}
+
+// Test a busy mail host moves the check on to the next one, while a
+// permanent refusal does not
+func TestSmtpCheck_GreetingNextHost(t *testing.T) {
+	for greeting, want := range map[string]StatusId{
+		"421 4.3.2 too busy, try later": StatusIdLive,
+		"554 5.7.1 no service for you":  StatusIdCheckError,
+	} {
+		replies := map[string]string{"live": "250 2.1.5 OK"}
+		first := newSmtpStandIn(t, greeting, replies, "550 5.1.1 user unknown")
+		_, port, _ := net.SplitHostPort(first.listener.Addr().String())
+		newSmtpStandInAt(t, net.JoinHostPort("127.0.0.2", port), "220 stand-in ESMTP", replies, "550 5.1.1 user unknown")
+
+		checker := first.checker()
+		checker.lookupMX = func(ctx context.Context, domain string) ([]*net.MX, error) {
+			return []*net.MX{{Host: "127.0.0.1.", Pref: 10}, {Host: "127.0.0.2.", Pref: 20}}, nil
+		}
+		if result := checker.CheckDetail(context.Background(), "live@example.com"); result.Status.Id != want {
+			t.Errorf("%s: expected %v, got %v (%v)", greeting, want, result.Status.Id, result.Err)
+		}
+	}
+}
+
+// Test the Check function for an invalid email and an unreachable host
+func TestSmtpCheck_Errors(t *testing.T) {
+	checker := newSmtpMail()
+	if status := checker.Check("invalid-email-format"); status.Id != StatusIdFormatInvalid {
+		t.Fatalf("expected StatusIdFormatInvalid, got %v", status.Id)
+	}
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+	checker.port = port
+	checker.lookupMX = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		return []*net.MX{{Host: "127.0.0.1"}}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if result := checker.CheckDetail(ctx, "someone@example.com"); result.Status.Id != StatusIdCheckError || result.Err == nil {
+		t.Fatalf("expected StatusIdCheckError with an error, got %+v", result)
+	}
+}
+
+// Test getMailHosts falls back to the domain and handles IP literals
+func TestSmtpGetMailHosts(t *testing.T) {
+	checker := newSmtpMail()
+	checker.lookupMX = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: domain, IsNotFound: true}
+	}
+	hosts, err := checker.getMailHosts(context.Background(), "example.com")
+	if err != nil || len(hosts) != 1 || hosts[0] != "example.com" {
+		t.Fatalf("expected implicit MX, got %v (%v)", hosts, err)
+	}
+
+	hosts, err = checker.getMailHosts(context.Background(), "[IPv6:::1]")
+	if err != nil || len(hosts) != 1 || hosts[0] != "::1" {
+		t.Fatalf("expected IP literal host, got %v (%v)", hosts, err)
+	}
+}
@@ -0,0 +1,203 @@
+package mail_checker
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	streamCsvHeader   = []string{"input", "email", "kind", "status_id", "status", "reason", "error", "attempts", "latency_ms"}
+	errStreamLineLong = tagError(ErrInvalidFormat, fmt.Errorf("line longer than %d bytes", streamLineMaxBytes))
+)
+
+// CheckStream reads one address per line from r, checks them with a bounded
+// pool of workers sharing the checker and writes each result to w as soon as
+// it can, as NDJSON (the default) or CSV. With opts.Ordered the results keep
+// the input order, otherwise they are written as they finish. At most a
+// small window of addresses is held in memory, whatever the input size. A
+// line too long to be an address gets a StatusIdFormatInvalid result with
+// its beginning as input.
+func CheckStream(ctx context.Context, checker Checker, r io.Reader, w io.Writer, opts StreamOptions) error {
+	encode, err := newStreamEncoder(w, opts.Format)
+	if err != nil {
+		return err
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = batchConcurrencyDefault
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type streamItem struct {
+		seq    int
+		email  string
+		err    error
+		result CheckResult
+	}
+	// Each address holds a slot from the moment it is read until its result
+	// is written, which bounds the work in flight and the reorder buffer.
+	slots := make(chan struct{}, concurrency*streamWindowFactor)
+	jobs := make(chan streamItem)
+	done := make(chan streamItem)
+
+	var workers sync.WaitGroup
+	workers.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer workers.Done()
+			for item := range jobs {
+				if item.err != nil {
+					item.result = newCheckResult(checkerKind(checker), item.email).finish(StatusIdFormatInvalid, "", item.err)
+				} else {
+					item.result = checker.CheckDetail(ctx, item.email)
+				}
+				done <- item
+			}
+		}()
+	}
+
+	writeErr := make(chan error, 1)
+	go func() {
+		var err error
+		write := func(result CheckResult) {
+			if err == nil {
+				if err = encode(result); err != nil {
+					cancel()
+				}
+			}
+			<-slots
+		}
+
+		pending := make(map[int]CheckResult)
+		next := 0
+		for item := range done {
+			if !opts.Ordered {
+				write(item.result)
+				continue
+			}
+			pending[item.seq] = item.result
+			for result, ok := pending[next]; ok; result, ok = pending[next] {
+				delete(pending, next)
+				next++
+				write(result)
+			}
+		}
+		writeErr <- err
+	}()
+
+	reader := bufio.NewReaderSize(r, streamLineMaxBytes)
+	seq := 0
+	var readErr error
+read:
+	for readErr == nil {
+		var line string
+		var long bool
+		line, long, readErr = readStreamLine(reader)
+		email := strings.TrimSpace(line)
+		if email == "" {
+			continue
+		}
+		item := streamItem{seq: seq, email: email}
+		if long {
+			item.email = strings.ToValidUTF8(email[:min(len(email), addressMaxLength)], "")
+			item.err = errStreamLineLong
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			break read
+		}
+		jobs <- item
+		seq++
+	}
+	close(jobs)
+	workers.Wait()
+	close(done)
+
+	if err = <-writeErr; err != nil {
+		return err
+	}
+	if readErr != nil && readErr != io.EOF {
+		return readErr
+	}
+	return parent.Err()
+}
+
+// readStreamLine reads a line, keeping only the first streamLineMaxBytes of
+// a longer one, which it reports as long.
+func readStreamLine(reader *bufio.Reader) (line string, long bool, err error) {
+	slice, err := reader.ReadSlice('\n')
+	line = string(slice)
+	for errors.Is(err, bufio.ErrBufferFull) {
+		long = true
+		_, err = reader.ReadSlice('\n')
+	}
+	return line, long, err
+}
+
+func newStreamEncoder(w io.Writer, format StreamFormat) (func(result CheckResult) error, error) {
+	switch format {
+	case "", StreamFormatNDJSON:
+		encoder := json.NewEncoder(w)
+		return func(result CheckResult) error {
+			return encoder.Encode(newStreamRecord(result))
+		}, nil
+	case StreamFormatCSV:
+		writer := csv.NewWriter(w)
+		header := false
+		return func(result CheckResult) error {
+			if !header {
+				header = true
+				if err := writer.Write(streamCsvHeader); err != nil {
+					return err
+				}
+			}
+			record := newStreamRecord(result)
+			err := writer.Write([]string{
+				record.Input,
+				record.Email,
+				string(record.Kind),
+				strconv.Itoa(int(record.StatusId)),
+				string(record.Status),
+				record.Reason,
+				record.Error,
+				strconv.Itoa(record.Attempts),
+				strconv.FormatInt(record.LatencyMs, 10),
+			})
+			if err != nil {
+				return err
+			}
+			writer.Flush()
+			return writer.Error()
+		}, nil
+	}
+	return nil, ErrUnknownStreamFormat
+}
+
+func newStreamRecord(result CheckResult) streamRecord {
+	record := streamRecord{
+		Input:     result.Input,
+		Email:     result.Email,
+		Kind:      result.Kind,
+		StatusId:  result.Status.Id,
+		Status:    result.Status.Name,
+		Reason:    result.Reason,
+		Attempts:  result.Attempts,
+		LatencyMs: result.Latency.Milliseconds(),
+	}
+	if result.Err != nil {
+		record.Error = result.Err.Error()
+	}
+	return record
+}
@@ -0,0 +1,226 @@
+package mail_checker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lineSource generates n addresses lazily, like a large file
+type lineSource struct {
+	n, i int
+	buf  []byte
+}
+
+func (s *lineSource) Read(p []byte) (int, error) {
+	for len(s.buf) < len(p) && s.i < s.n {
+		s.buf = append(s.buf, fmt.Sprintf("user%d@example.com\n", s.i)...)
+		s.i++
+	}
+	if len(s.buf) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// countingWriter counts the written lines
+type countingWriter struct {
+	lines atomic.Int64
+	buf   bytes.Buffer
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.lines.Add(int64(bytes.Count(p, []byte("\n"))))
+	return w.buf.Write(p)
+}
+
+// Test CheckStream writes NDJSON results in input order
+func TestCheckStream_Ordered(t *testing.T) {
+	checker := &stubChecker{checkFunc: func(ctx context.Context, email string) StatusId {
+		// Later addresses finish first.
+		var i int
+		fmt.Sscanf(email, "user%d@", &i)
+		time.Sleep(time.Duration(20-i%20) * 100 * time.Microsecond)
+		if i%2 == 0 {
+			return StatusIdLive
+		}
+		return StatusIdNotExists
+	}}
+
+	input := &lineSource{n: 200}
+	var out bytes.Buffer
+	err := CheckStream(context.Background(), checker, input, &out, StreamOptions{Concurrency: 8, Ordered: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	scanner := bufio.NewScanner(&out)
+	i := 0
+	for scanner.Scan() {
+		var record streamRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d: invalid JSON %q", i, scanner.Text())
+		}
+		if record.Input != fmt.Sprintf("user%d@example.com", i) {
+			t.Fatalf("line %d: expected input order, got %s", i, record.Input)
+		}
+		want := StatusIdNotExists
+		if i%2 == 0 {
+			want = StatusIdLive
+		}
+		if record.StatusId != want {
+			t.Fatalf("line %d: expected %v, got %v", i, want, record.StatusId)
+		}
+		i++
+	}
+	if i != 200 {
+		t.Fatalf("expected 200 lines, got %d", i)
+	}
+}
+
+// Test an over-long line gets a format error and the stream goes on
+func TestCheckStream_LongLine(t *testing.T) {
+	var checked atomic.Int32
+	checker := &stubChecker{checkFunc: func(ctx context.Context, email string) StatusId {
+		checked.Add(1)
+		return StatusIdLive
+	}}
+	input := "first@example.com\n" + strings.Repeat("x", 70000) + "\nlast@example.com"
+	var out bytes.Buffer
+	if err := CheckStream(context.Background(), checker, strings.NewReader(input), &out, StreamOptions{Ordered: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var records []streamRecord
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var record streamRecord
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 3 || records[0].Input != "first@example.com" || records[2].Input != "last@example.com" {
+		t.Fatalf("expected the three lines to get a result, got %+v", records)
+	}
+	if records[1].StatusId != StatusIdFormatInvalid || len(records[1].Input) != addressMaxLength || records[1].Error == "" {
+		t.Errorf("expected a format error for the long line, got %+v", records[1])
+	}
+	if checked.Load() != 2 {
+		t.Errorf("expected the long line not to be checked, got %d checks", checked.Load())
+	}
+}
+
+// Test CheckStream writes CSV results as they finish
+func TestCheckStream_UnorderedCsv(t *testing.T) {
+	checker := &stubChecker{kind: MailKindSmtp}
+	input := strings.NewReader("a@example.com\n\n  b@example.com  \nbad@@example.com\n")
+	var out bytes.Buffer
+	err := CheckStream(context.Background(), checker, input, &out, StreamOptions{Format: StreamFormatCSV})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 4 || strings.Join(rows[0], ",") != strings.Join(streamCsvHeader, ",") {
+		t.Fatalf("expected a header and 3 rows, got %v", rows)
+	}
+	seen := map[string]bool{}
+	for _, row := range rows[1:] {
+		seen[row[0]] = true
+		if row[2] != string(MailKindSmtp) {
+			t.Fatalf("expected kind %s, got %v", MailKindSmtp, row)
+		}
+	}
+	for _, email := range []string{"a@example.com", "b@example.com", "bad@@example.com"} {
+		if !seen[email] {
+			t.Fatalf("expected a row for %s, got %v", email, rows)
+		}
+	}
+}
+
+// Test CheckStream keeps a bounded number of addresses in flight
+func TestCheckStream_BoundedMemory(t *testing.T) {
+	out := &countingWriter{}
+	var started, peak atomic.Int64
+	checker := &stubChecker{checkFunc: func(ctx context.Context, email string) StatusId {
+		inFlight := started.Add(1) - out.lines.Load()
+		for {
+			p := peak.Load()
+			if inFlight <= p || peak.CompareAndSwap(p, inFlight) {
+				break
+			}
+		}
+		return StatusIdLive
+	}}
+
+	opts := StreamOptions{Concurrency: 4, Ordered: true}
+	if err := CheckStream(context.Background(), checker, &lineSource{n: 20000}, out, opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if out.lines.Load() != 20000 {
+		t.Fatalf("expected 20000 lines, got %d", out.lines.Load())
+	}
+	if window := int64(opts.Concurrency * streamWindowFactor); peak.Load() > window {
+		t.Fatalf("expected at most %d addresses in flight, got %d", window, peak.Load())
+	}
+}
+
+// Test CheckStream stops reading when the context is cancelled
+func TestCheckStream_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int64
+	checker := &stubChecker{checkFunc: func(ctx context.Context, email string) StatusId {
+		if calls.Add(1) == 10 {
+			cancel()
+		}
+		return StatusIdLive
+	}}
+
+	err := CheckStream(ctx, checker, &lineSource{n: 100000}, io.Discard, StreamOptions{Concurrency: 2})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if calls.Load() > 20 {
+		t.Fatalf("expected the stream to stop early, got %d checks", calls.Load())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+// Test CheckStream reports write errors and unknown formats
+func TestCheckStream_Errors(t *testing.T) {
+	checker := &stubChecker{}
+	err := CheckStream(context.Background(), checker, &lineSource{n: 1000}, failingWriter{}, StreamOptions{Ordered: true})
+	if err == nil || err.Error() != "disk full" {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+
+	err = CheckStream(context.Background(), checker, &lineSource{n: 1000}, failingWriter{}, StreamOptions{Format: StreamFormatCSV})
+	if err == nil || err.Error() != "disk full" {
+		t.Fatalf("expected the CSV write error, got %v", err)
+	}
+
+	err = CheckStream(context.Background(), checker, strings.NewReader(""), io.Discard, StreamOptions{Format: "xml"})
+	if !errors.Is(err, ErrUnknownStreamFormat) {
+		t.Fatalf("expected ErrUnknownStreamFormat, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package mail_checker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithBootstrapTimeout sets the time limit of getting a provider session,
+// the signup page fetch, instead of the WithTimeout one. It includes the
+// wait for the rate limiter and for a fetch shared with concurrent checks.
+func WithBootstrapTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeouts.bootstrap = timeout
+	}
+}
+
+// WithProbeTimeout sets the time limit of the availability request, instead
+// of the WithTimeout one. It includes the wait for the rate limiter.
+func WithProbeTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeouts.probe = timeout
+	}
+}
+
+// WithCheckTimeout sets the time budget of a whole check attempt, the
+// bootstrap and the probe together. There is no budget by default.
+func WithCheckTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeouts.check = timeout
+	}
+}
+
+// phaseTimeouts returns the time limits of the phases. Once one of them is
+// set, the other one keeps the WithTimeout limit of the built client.
+func (o options) phaseTimeouts() phaseTimeouts {
+	timeouts := o.timeouts
+	if o.client == nil && (timeouts.bootstrap > 0 || timeouts.probe > 0) {
+		if timeouts.bootstrap == 0 {
+			timeouts.bootstrap = o.timeout
+		}
+		if timeouts.probe == 0 {
+			timeouts.probe = o.timeout
+		}
+	}
+	return timeouts
+}
+
+// withPhaseTimeout limits ctx to the time limit of the phase, if any.
+func withPhaseTimeout(ctx context.Context, phase CheckPhase, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, &PhaseTimeoutError{Phase: phase, Timeout: timeout})
+}
+
+// runPhases runs a check attempt: it gets a session with fetch, then probes
+// the address with it, each phase within its time limit and all of them
+// within the check budget. A session the probe reports as rejected is
+// dropped and fetched again, sessionRejectedRetries times at most.
+func runPhases[T any](ctx context.Context, result CheckResult, logger Logger, session *sessionCache[T],
+	fetch func(ctx context.Context) (T, error),
+	probe func(ctx context.Context, result CheckResult, value T) (CheckResult, bool),
+	timeouts phaseTimeouts) CheckResult {
+	ctx, cancel := withPhaseTimeout(ctx, CheckPhaseCheck, timeouts.check)
+	defer cancel()
+
+	for attempt := 0; ; attempt++ {
+		bootstrapCtx, cancel := withPhaseTimeout(ctx, CheckPhaseBootstrap, timeouts.bootstrap)
+		value, generation, err := session.get(bootstrapCtx, fetch)
+		cancel()
+		if err != nil {
+			logger.Error("Get session failed", logKeyPhase, logPhaseBootstrap, logKeyError, err)
+			return result.finish(StatusIdCheckError, "", err).timedOut(bootstrapCtx, CheckPhaseBootstrap)
+		}
+
+		var rejected bool
+		probeCtx, cancel := withPhaseTimeout(ctx, CheckPhaseProbe, timeouts.probe)
+		result, rejected = probe(probeCtx, result, value)
+		cancel()
+		if result = result.timedOut(probeCtx, CheckPhaseProbe); !rejected || attempt >= sessionRejectedRetries {
+			return result
+		}
+		logger.Debug("Session rejected, fetching a new one", logKeyPhase, logPhaseProbe, logKeyError, result.Err)
+		session.invalidate(generation)
+	}
+}
+
+// timedOut reports the phase a timeout of the result happened in: the phase
+// whose limit expired ctx, or the running phase for the other timeouts.
+func (r CheckResult) timedOut(ctx context.Context, phase CheckPhase) CheckResult {
+	if r.Err == nil || !errors.Is(r.Err, ErrTimeout) {
+		return r
+	}
+	// The HTTP client returns the cause of the expired context as is.
+	var timeoutErr *PhaseTimeoutError
+	if !errors.As(r.Err, &timeoutErr) {
+		timeoutErr = &PhaseTimeoutError{Phase: phase, Err: r.Err}
+		var cause *PhaseTimeoutError
+		if errors.As(context.Cause(ctx), &cause) {
+			timeoutErr.Phase, timeoutErr.Timeout = cause.Phase, cause.Timeout
+		}
+		r.Err = timeoutErr
+	}
+	r.TimeoutPhase = timeoutErr.Phase
+	return r
+}
@@ -0,0 +1,76 @@
+package mail_checker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// checkSlowMicrosoft checks an address against a Microsoft stand-in taking
+// the given time to answer the signup page and the availability probe
+func checkSlowMicrosoft(t *testing.T, bootstrap, probe time.Duration, opts ...Option) CheckResult {
+	server := microsoftStandIn{bootstrapDelay: bootstrap, probeDelay: probe}.start(t)
+	result, err := checkMicrosoftStandIn(server, append(opts, trustStandIn(server))...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+// Test the result reports the phase whose time limit expired
+func TestPhaseTimeouts(t *testing.T) {
+	const short, long = 50 * time.Millisecond, 2 * time.Second
+	cases := []struct {
+		name      string
+		bootstrap time.Duration
+		probe     time.Duration
+		opts      []Option
+		phase     CheckPhase
+		timeout   time.Duration
+	}{
+		{"bootstrap", 4 * short, 0, []Option{WithBootstrapTimeout(short)}, CheckPhaseBootstrap, short},
+		{"probe", 0, 4 * short, []Option{WithProbeTimeout(short)}, CheckPhaseProbe, short},
+		{"check", 3 * short, 3 * short, []Option{WithBootstrapTimeout(long), WithProbeTimeout(long), WithCheckTimeout(4 * short)}, CheckPhaseCheck, 4 * short},
+		{"client", 0, 4 * short, []Option{WithTimeout(short)}, CheckPhaseProbe, 0},
+	}
+	for _, c := range cases {
+		result := checkSlowMicrosoft(t, c.bootstrap, c.probe, c.opts...)
+		if result.Status.Id != StatusIdCheckError || result.TimeoutPhase != c.phase {
+			t.Errorf("%s: expected a %s timeout, got %v in %q (%v)", c.name, c.phase, result.Status.Id, result.TimeoutPhase, result.Err)
+			continue
+		}
+		var timeoutErr *PhaseTimeoutError
+		if !errors.As(result.Err, &timeoutErr) || timeoutErr.Timeout != c.timeout || !errors.Is(result.Err, ErrTimeout) {
+			t.Errorf("%s: expected a PhaseTimeoutError after %v, got %v", c.name, c.timeout, result.Err)
+		}
+	}
+}
+
+// Test a bootstrap timeout above WithTimeout lets a slow signup page through
+// while the probe keeps the WithTimeout limit
+func TestPhaseTimeoutsOverrideClientTimeout(t *testing.T) {
+	const short = 100 * time.Millisecond
+	result := checkSlowMicrosoft(t, 3*short, 0, WithTimeout(short), WithBootstrapTimeout(time.Second))
+	if result.Status.Id != StatusIdLive || result.TimeoutPhase != "" {
+		t.Fatalf("expected StatusIdLive, got %v in %q (%v)", result.Status.Id, result.TimeoutPhase, result.Err)
+	}
+
+	result = checkSlowMicrosoft(t, 0, 3*short, WithTimeout(short), WithBootstrapTimeout(time.Second))
+	var timeoutErr *PhaseTimeoutError
+	if result.TimeoutPhase != CheckPhaseProbe || !errors.As(result.Err, &timeoutErr) || timeoutErr.Timeout != short {
+		t.Errorf("expected the probe to time out after %v, got %q (%v)", short, result.TimeoutPhase, result.Err)
+	}
+}
+
+// Test invalid phase timeouts are rejected when the checker is built
+func TestPhaseTimeoutsErrors(t *testing.T) {
+	invalid := map[MailKind][]Option{
+		MailKindAuto: {WithProbeTimeout(-time.Second)},
+		MailKindSmtp: {WithCheckTimeout(time.Second)},
+	}
+	for kind, opts := range invalid {
+		if _, err := NewWithOptions(kind, opts...); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", kind, err)
+		}
+	}
+}
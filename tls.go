@@ -0,0 +1,90 @@
+package mail_checker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// WithRootCAs makes the checker trust only the certificate authorities of the
+// pool, for the providers and for an HTTPS proxy.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(o *options) {
+		o.tls.rootCAs = pool
+	}
+}
+
+// WithRootCAFile makes the checker trust the PEM certificates of the file, like
+// the root CA of a proxy intercepting HTTPS, on top of the system roots or of
+// the pool given with WithRootCAs.
+func WithRootCAFile(path string) Option {
+	return func(o *options) {
+		o.tls.rootCAFile = path
+	}
+}
+
+// WithMinTLSVersion sets the lowest TLS version the checker accepts, such as
+// tls.VersionTLS12.
+func WithMinTLSVersion(version uint16) Option {
+	return func(o *options) {
+		o.tls.minVersion = version
+	}
+}
+
+// WithClientCertificate makes the checker present the certificate to the
+// servers asking for one, like a proxy authenticating its clients with TLS.
+func WithClientCertificate(certificate tls.Certificate) Option {
+	return func(o *options) {
+		o.tls.certificates = append(o.tls.certificates, certificate)
+	}
+}
+
+// WithClientCertificateFile is WithClientCertificate with the certificate and
+// its key read from PEM files.
+func WithClientCertificateFile(certFile, keyFile string) Option {
+	return func(o *options) {
+		o.tls.certFile, o.tls.keyFile = certFile, keyFile
+	}
+}
+
+func (t tlsOptions) isSet() bool {
+	return t.rootCAs != nil || t.rootCAFile != "" || t.minVersion != 0 || len(t.certificates) > 0 || t.certFile != ""
+}
+
+// config returns the TLS configuration of the options, or nil for the
+// default one.
+func (t tlsOptions) config() (*tls.Config, error) {
+	if !t.isSet() {
+		return nil, nil
+	}
+	config := &tls.Config{
+		RootCAs:      t.rootCAs,
+		MinVersion:   t.minVersion,
+		Certificates: append([]tls.Certificate(nil), t.certificates...),
+	}
+
+	if t.rootCAFile != "" {
+		pem, err := os.ReadFile(t.rootCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: root CA file: %s", ErrInvalidOption, err.Error())
+		}
+		if config.RootCAs != nil {
+			config.RootCAs = config.RootCAs.Clone()
+		} else if config.RootCAs, err = x509.SystemCertPool(); err != nil {
+			config.RootCAs = x509.NewCertPool()
+		}
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no PEM certificate in the root CA file %s", ErrInvalidOption, t.rootCAFile)
+		}
+	}
+
+	if t.certFile != "" {
+		certificate, err := tls.LoadX509KeyPair(t.certFile, t.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: client certificate: %s", ErrInvalidOption, err.Error())
+		}
+		config.Certificates = append(config.Certificates, certificate)
+	}
+	return config, nil
+}
@@ -0,0 +1,139 @@
+package mail_checker
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePem(t *testing.T, name, kind string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Test a custom root CA, as a file or a pool, makes an intercepted endpoint trusted
+func TestTLSRootCAs(t *testing.T) {
+	server := microsoftStandIn{}.start(t)
+
+	result, err := checkMicrosoftStandIn(server)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status.Id != StatusIdCheckError {
+		t.Fatalf("expected the unknown CA to fail the check, got %v", result.Status.Id)
+	}
+
+	caFile := writePem(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	for name, opt := range map[string]Option{"file": WithRootCAFile(caFile), "pool": WithRootCAs(pool)} {
+		result, err = checkMicrosoftStandIn(server, opt)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if result.Status.Id != StatusIdLive {
+			t.Errorf("%s: expected StatusIdLive, got %v (%v)", name, result.Status.Id, result.Err)
+		}
+	}
+}
+
+// Test the minimum TLS version refuses an older server
+func TestTLSMinVersion(t *testing.T) {
+	server := microsoftStandIn{setupTLS: func(config *tls.Config) {
+		config.MaxVersion = tls.VersionTLS12
+	}}.start(t)
+	trust := trustStandIn(server)
+
+	result, err := checkMicrosoftStandIn(server, trust, WithMinTLSVersion(tls.VersionTLS12))
+	if err != nil || result.Status.Id != StatusIdLive {
+		t.Fatalf("expected StatusIdLive with TLS 1.2, got %v (%v, %v)", result.Status.Id, result.Err, err)
+	}
+	result, err = checkMicrosoftStandIn(server, trust, WithMinTLSVersion(tls.VersionTLS13))
+	if err != nil || result.Status.Id != StatusIdCheckError {
+		t.Errorf("expected TLS 1.3 to be required, got %v (%v)", result.Status.Id, err)
+	}
+}
+
+// Test the client certificate is presented to a server requiring one
+func TestTLSClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mail-checker-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := microsoftStandIn{setupTLS: func(config *tls.Config) {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = x509.NewCertPool()
+		config.ClientCAs.AddCert(certificate)
+	}}.start(t)
+	trust := trustStandIn(server)
+
+	result, err := checkMicrosoftStandIn(server, trust)
+	if err != nil || result.Status.Id != StatusIdCheckError {
+		t.Fatalf("expected the server to refuse a client without certificate, got %v (%v)", result.Status.Id, err)
+	}
+
+	options := map[string]Option{
+		"certificate": WithClientCertificate(tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}),
+		"file":        WithClientCertificateFile(writePem(t, "client.pem", "CERTIFICATE", der), writePem(t, "client.key", "EC PRIVATE KEY", keyDer)),
+	}
+	for name, opt := range options {
+		result, err = checkMicrosoftStandIn(server, trust, opt)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if result.Status.Id != StatusIdLive {
+			t.Errorf("%s: expected StatusIdLive, got %v (%v)", name, result.Status.Id, result.Err)
+		}
+	}
+}
+
+// Test invalid TLS options are rejected when the checker is built
+func TestTLSOptionsErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	invalid := map[string][]Option{
+		"missing CA file":        {WithRootCAFile(missing)},
+		"CA file without PEM":    {WithRootCAFile(os.DevNull)},
+		"missing client cert":    {WithClientCertificateFile(missing, missing)},
+		"unknown version":        {WithMinTLSVersion(0x0200)},
+		"TLS with custom client": {WithHTTPClient(http.DefaultClient), WithMinTLSVersion(tls.VersionTLS12)},
+	}
+	for name, opts := range invalid {
+		if _, err := NewWithOptions(MailKindMicrosoft, opts...); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", name, err)
+		}
+	}
+}
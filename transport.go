@@ -0,0 +1,59 @@
+package mail_checker
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultTransportPolicy is the connection pool policy of the checkers built
+// without WithTransportPolicy. Connections are kept alive and shared by the
+// concurrent checks of a checker, and HTTP/2 is negotiated when the upstream
+// offers it.
+var DefaultTransportPolicy = TransportPolicy{
+	MaxIdleConns:        transportMaxIdleConnsDefault,
+	MaxIdleConnsPerHost: transportMaxIdleConnsPerHostDefault,
+	IdleConnTimeout:     transportIdleConnTimeoutDefault,
+}
+
+// WithTransportPolicy tunes the connection pool of the checker instead of
+// DefaultTransportPolicy. It cannot be combined with WithHTTPClient and does
+// not apply to the smtp kind.
+func WithTransportPolicy(policy TransportPolicy) Option {
+	return func(o *options) {
+		o.transport = &policy
+	}
+}
+
+// transportPolicy returns the connection pool policy of a checker built with
+// the options.
+func (o options) transportPolicy() TransportPolicy {
+	if o.transport != nil {
+		return *o.transport
+	}
+	return DefaultTransportPolicy
+}
+
+// newTransport makes a transport using the HTTP and HTTPS proxies of the
+// environment, like http.DefaultTransport, until setTransportProxy sets one.
+func newTransport(policy TransportPolicy, dialer *dialer) *http.Transport {
+	return &http.Transport{
+		Proxy:                  http.ProxyFromEnvironment,
+		OnProxyConnectResponse: checkProxyConnect,
+		DialContext:            dialer.DialContext,
+		ForceAttemptHTTP2:      !policy.DisableHTTP2,
+		MaxIdleConns:           policy.MaxIdleConns,
+		MaxIdleConnsPerHost:    policy.MaxIdleConnsPerHost,
+		MaxConnsPerHost:        policy.MaxConnsPerHost,
+		IdleConnTimeout:        policy.IdleConnTimeout,
+		TLSHandshakeTimeout:    transportTLSHandshakeTimeoutDefault,
+		ExpectContinueTimeout:  time.Second,
+	}
+}
+
+// closeBody reads what is left of a small response body before closing it,
+// so that the connection goes back to the pool instead of being dropped.
+func closeBody(body io.ReadCloser) {
+	_, _ = io.CopyN(io.Discard, body, transportDrainMaxBytes)
+	_ = body.Close()
+}
@@ -0,0 +1,217 @@
+package mail_checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// microsoftStandIn serves the Microsoft signup page and availability
+// endpoint over TLS, answering each after its delay. It counts the
+// connections it accepts in conns, if set, and lets setupTLS configure the
+// server before it starts.
+type microsoftStandIn struct {
+	conns          *atomic.Int32
+	bootstrapDelay time.Duration
+	probeDelay     time.Duration
+	setupTLS       func(config *tls.Config)
+}
+
+func (s microsoftStandIn) start(tb testing.TB) *httptest.Server {
+	signup, _ := url.Parse(hotmailUrlSignup)
+	check, _ := url.Parse(hotmailUrlCheckAvailable)
+	sleep := func(r *http.Request, delay time.Duration) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(signup.Path, func(w http.ResponseWriter, r *http.Request) {
+		sleep(r, s.bootstrapDelay)
+		w.Header().Set("Set-Cookie", "amsc=testCookie; path=/;")
+		io.WriteString(w, `<script>var ServerData={"apiCanary":"testCanary"};</script>`)
+	})
+	mux.HandleFunc(check.Path, func(w http.ResponseWriter, r *http.Request) {
+		sleep(r, s.probeDelay)
+		io.Copy(io.Discard, r.Body)
+		io.WriteString(w, `{"isAvailable":false}`)
+	})
+
+	server := httptest.NewUnstartedServer(mux)
+	server.EnableHTTP2 = true
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew && s.conns != nil {
+			s.conns.Add(1)
+		}
+	}
+	server.TLS = &tls.Config{}
+	if s.setupTLS != nil {
+		s.setupTLS(server.TLS)
+	}
+	server.StartTLS()
+	tb.Cleanup(server.Close)
+	return server
+}
+
+// checkMicrosoftStandIn checks an address with a Microsoft checker built with
+// the options and sending its requests to the stand-in
+func checkMicrosoftStandIn(server *httptest.Server, opts ...Option) (CheckResult, error) {
+	signup, _ := url.Parse(hotmailUrlSignup)
+	check, _ := url.Parse(hotmailUrlCheckAvailable)
+	opts = append(opts, WithEndpoints(Endpoints{Bootstrap: server.URL + signup.Path, Probe: server.URL + check.Path}), WithRateLimit(0, 0))
+	checker, err := NewWithOptions(MailKindMicrosoft, opts...)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	return checker.CheckDetail(context.Background(), "test@hotmail.com"), nil
+}
+
+// trustStandIn trusts the certificate of the stand-in
+func trustStandIn(server *httptest.Server) Option {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return WithRootCAs(pool)
+}
+
+// newStandInTransport returns a transport built like the production one that
+// dials the stand-in server for every host
+func newStandInTransport(server *httptest.Server, policy TransportPolicy) *http.Transport {
+	transport := newTransport(policy, newDialer(dialOptions{}))
+	transport.Proxy = nil
+	transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	transport.TLSClientConfig.ServerName = "example.com"
+	dial := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dial(ctx, network, server.Listener.Addr().String())
+	}
+	return transport
+}
+
+// Test sequential checks share a single connection
+func TestTransportReusesConnections(t *testing.T) {
+	var conns atomic.Int32
+	server := microsoftStandIn{conns: &conns}.start(t)
+	transport := newStandInTransport(server, DefaultTransportPolicy)
+	defer transport.CloseIdleConnections()
+	checker := &microsoftMail{client: &http.Client{Transport: transport}}
+
+	for i := 0; i < 20; i++ {
+		if status := checker.Check("test@hotmail.com"); status.Id != StatusIdLive {
+			t.Fatalf("check %d: expected StatusIdLive, got %v", i, status.Id)
+		}
+	}
+	if conns.Load() != 1 {
+		t.Fatalf("expected 1 connection, got %d", conns.Load())
+	}
+}
+
+// Test concurrent checks reuse connections over HTTP/1.1 and HTTP/2
+func TestTransportConcurrentReuse(t *testing.T) {
+	for _, disableHTTP2 := range []bool{false, true} {
+		var conns atomic.Int32
+		server := microsoftStandIn{conns: &conns}.start(t)
+		policy := DefaultTransportPolicy
+		policy.DisableHTTP2 = disableHTTP2
+		transport := newStandInTransport(server, policy)
+		checker := &microsoftMail{client: &http.Client{Transport: transport}}
+
+		const workers = 8
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 10; i++ {
+					if status := checker.Check("test@hotmail.com"); status.Id != StatusIdLive {
+						t.Errorf("expected StatusIdLive, got %v", status.Id)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		transport.CloseIdleConnections()
+
+		// A request may dial while another is handing its connection back,
+		// so allow some slack over one connection per worker.
+		if conns.Load() > 2*workers {
+			t.Fatalf("HTTP/2 disabled %v: expected at most %d connections for 160 requests, got %d", disableHTTP2, 2*workers, conns.Load())
+		}
+	}
+}
+
+// Test WithTransportPolicy tunes the pool of the checker instead of the
+// default policy
+func TestWithTransportPolicy(t *testing.T) {
+	policy := TransportPolicy{MaxIdleConnsPerHost: 64, IdleConnTimeout: time.Minute, DisableHTTP2: true}
+	checker, err := NewWithOptions(MailKindYahoo, WithTransportPolicy(policy))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transport := checker.(*yahooMail).client.Transport.(*http.Transport)
+	if transport.MaxIdleConnsPerHost != 64 || transport.IdleConnTimeout != time.Minute || transport.ForceAttemptHTTP2 {
+		t.Errorf("expected the policy to tune the transport, got %d, %v and %v",
+			transport.MaxIdleConnsPerHost, transport.IdleConnTimeout, transport.ForceAttemptHTTP2)
+	}
+
+	if _, err = NewWithOptions(MailKindYahoo, WithHTTPClient(http.DefaultClient), WithTransportPolicy(policy)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("with a client: expected ErrInvalidOption, got %v", err)
+	}
+	if _, err = NewWithOptions(MailKindSmtp, WithTransportPolicy(policy)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("smtp: expected ErrInvalidOption, got %v", err)
+	}
+}
+
+// Benchmark checks against a TLS stand-in with and without connection reuse
+func BenchmarkTransport(b *testing.B) {
+	benchmarks := []struct {
+		name      string
+		policy    TransportPolicy
+		keepAlive bool
+	}{
+		{name: "KeepAliveHTTP2", policy: DefaultTransportPolicy, keepAlive: true},
+		{name: "KeepAliveHTTP1", policy: TransportPolicy{
+			MaxIdleConns:        transportMaxIdleConnsDefault,
+			MaxIdleConnsPerHost: transportMaxIdleConnsPerHostDefault,
+			IdleConnTimeout:     transportIdleConnTimeoutDefault,
+			DisableHTTP2:        true,
+		}, keepAlive: true},
+		{name: "NewConnectionPerRequest", policy: DefaultTransportPolicy},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var conns atomic.Int32
+			server := microsoftStandIn{conns: &conns}.start(b)
+			transport := newStandInTransport(server, bm.policy)
+			transport.DisableKeepAlives = !bm.keepAlive
+			defer transport.CloseIdleConnections()
+			checker := &microsoftMail{
+				client:  &http.Client{Transport: transport},
+				session: newSessionCache[microsoftSession](SessionPolicy{}),
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if status := checker.Check("test@hotmail.com"); status.Id != StatusIdLive {
+						b.Errorf("expected StatusIdLive, got %v", status.Id)
+					}
+				}
+			})
+			b.ReportMetric(float64(conns.Load())/float64(b.N), "conns/op")
+		})
+	}
+}
@@ -1,10 +1,10 @@
 package mail_checker
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"github.com/google/go-querystring/query"
-	log "github.com/sirupsen/logrus"
 	"io"
 	"net/http"
 	"regexp"
@@ -12,66 +12,114 @@ import (
 )
 
 type yahooMail struct {
-	client *http.Client
+	client    *http.Client
+	limiter   *RateLimiter
+	session   *sessionCache[yahooBodyChecker]
+	endpoints *Endpoints
+	logger    Logger
+	timeouts  phaseTimeouts
+}
+
+func newYahooMail(client *http.Client) *yahooMail {
+	return &yahooMail{
+		client:  client,
+		limiter: limiterFor(MailKindYahoo),
+		session: newSessionCache[yahooBodyChecker](DefaultSessionPolicy),
+	}
+}
+
+func (y *yahooMail) apply(o options) *yahooMail {
+	y.limiter = o.rateLimiter(MailKindYahoo)
+	y.session = newSessionCache[yahooBodyChecker](o.sessionPolicy())
+	y.endpoints = o.endpoints
+	y.logger = o.logger
+	y.timeouts = o.phaseTimeouts()
+	return y
 }
 
 func (y *yahooMail) Check(email string) (status Status) {
-	arrDataEmail := strings.SplitN(email, "@", 2)
+	return y.CheckContext(context.Background(), email)
+}
 
-	if len(arrDataEmail) != 2 {
-		log.Errorf("Invalid email format: %s", email)
-		return getStatusById(StatusIdFormatInvalid)
-	}
+func (y *yahooMail) CheckContext(ctx context.Context, email string) (status Status) {
+	return y.CheckDetail(ctx, email).Status
+}
 
-	dataBody, err := y.getBodyData()
+func (y *yahooMail) CheckDetail(ctx context.Context, email string) (result CheckResult) {
+	result = newCheckResult(MailKindYahoo, email)
+	local, domain, err := result.normalize()
+	if err == nil {
+		err = checkLocalPartRule(MailKindYahoo, email, local)
+	}
+	logger := checkLogger(y.logger, MailKindYahoo, result.Email)
 	if err != nil {
-		log.Errorf("Error fetching body data: %v", err)
-		return getStatusById(StatusIdCheckError)
+		logger.Debug("Invalid address", logKeyPhase, logPhaseValidate, logKeyError, redactError(err))
+		return result.finish(StatusIdFormatInvalid, "", err)
+	}
+
+	probe := func(ctx context.Context, result CheckResult, dataBody yahooBodyChecker) (CheckResult, bool) {
+		return y.checkUserId(ctx, result, dataBody, domain)
 	}
+	return runPhases(ctx, result, logger, y.session, y.getBodyData, probe, y.timeouts)
+}
 
-	dataBody.UserId = email
-	dataBody.UseridDomain = arrDataEmail[1]
+// checkUserId validates the address with the session. The second value
+// reports that the upstream rejected the session.
+func (y *yahooMail) checkUserId(ctx context.Context, result CheckResult, dataBody yahooBodyChecker, domain string) (CheckResult, bool) {
+	dataBody.UserId = result.Email
+	dataBody.UseridDomain = domain
+	logger := checkLogger(y.logger, MailKindYahoo, result.Email)
 
 	data, err := query.Values(&dataBody)
 	if err != nil {
-		log.Errorf("Error encoding query data: %v", err)
-		return getStatusById(StatusIdCheckError)
+		logger.Error("Encode query data failed", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
 	}
 
 	body := strings.NewReader(data.Encode())
-	req, err := http.NewRequest(http.MethodPost, yahooCheckerUrlApi, body)
+	apiUrl := y.endpoints.probeOr(yahooCheckerUrlApi)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiUrl, body)
 	if err != nil {
-		log.Errorf("Error creating new request to %s: %v", yahooCheckerUrlApi, err)
-		return getStatusById(StatusIdCheckError)
+		logger.Error("Create request failed", logKeyPhase, logPhaseProbe, "url", apiUrl, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
 	}
 
 	req.Header.Set("Content-Type", `application/x-www-form-urlencoded; charset=UTF-8`)
 	req.Header.Set("Cookie", dataBody.Cookie)
 	req.Header.Set("X-Requested-With", `XMLHttpRequest`)
 
+	if err = y.limiter.Wait(ctx); err != nil {
+		return result.finish(StatusIdCheckError, "", err), false
+	}
 	resp, err := y.client.Do(req)
 	if err != nil {
-		log.Errorf("Error executing request: %v", err)
-		return getStatusById(StatusIdCheckError)
+		logger.Error("Exec request failed", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
+	}
+	defer closeBody(resp.Body)
+	if isSessionRejectedStatus(resp) {
+		return result.finish(StatusIdCheckError, "", newHttpStatusError(resp)), true
+	}
+	if err = checkHttpStatus(resp); err != nil {
+		logger.Error("Unexpected response", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
 	}
-	defer resp.Body.Close()
-	y.client.CloseIdleConnections()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Errorf("Error reading response body: %v", err)
-		return getStatusById(StatusIdCheckError)
+		logger.Error("Read response body failed", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), false
 	}
 
 	var responseData yahooResChecker
 	if err = json.Unmarshal(bodyBytes, &responseData); err != nil {
-		log.Errorf("Error unmarshaling response JSON: %v", err)
-		return getStatusById(StatusIdCheckError)
+		logger.Warn("Parse response failed", logKeyPhase, logPhaseProbe, logKeyError, err)
+		return result.finish(StatusIdCheckError, "", err), true
 	}
 
 	if responseData.Errors == nil {
-		log.Error("No errors field in response data")
-		return getStatusById(StatusIdCheckError)
+		logger.Warn("The errors field does not exist in the response", logKeyPhase, logPhaseProbe)
+		return result.finish(StatusIdCheckError, "", ErrYahooErrorsFieldMissing), true
 	}
 
 	for _, er := range responseData.Errors {
@@ -80,51 +128,59 @@ func (y *yahooMail) Check(email string) (status Status) {
 			case yahooTextDetectUnavailableMail,
 				yahooTextDetectNotUnavailableMail,
 				yahooTextDetectReservedWordPresentMail:
-				return getStatusById(StatusIdLive)
+				return result.finish(StatusIdLive, er.Error, nil), false
 			case yahooTextDetectErrorLengthTooShort,
 				yahooTextDetectErrorSomeSpecialCharNotAllow:
-				return getStatusById(StatusIdCheckError)
+				return result.finish(StatusIdFormatInvalid, er.Error, ErrYahooUserIdRejected), false
 			}
 		}
 	}
-	return getStatusById(StatusIdNotExists)
+	return result.finish(StatusIdNotExists, "", nil), false
 }
 
 func (y *yahooMail) detectValue(html, name string) (string, error) {
 	re := regexp.MustCompile(`(?m)value="(.*?)" name="` + name + `"`)
 	matches := re.FindStringSubmatch(html)
 	if len(matches) == 0 {
-		return "", errors.New("could not detect value for " + name)
+		return "", fmt.Errorf("could not detect value for %s: %w", name, ErrYahooGetSessionValue)
 	}
 	return matches[1], nil
 }
 
-func (y *yahooMail) getBodyData() (yahooBodyChecker, error) {
-	req, err := http.NewRequest(http.MethodGet, yahooCreateAccountUrl, nil)
+func (y *yahooMail) getBodyData(ctx context.Context) (yahooBodyChecker, error) {
+	createAccountUrl := y.endpoints.bootstrapOr(yahooCreateAccountUrl)
+	logger := checkLogger(y.logger, MailKindYahoo, "")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, createAccountUrl, nil)
 	if err != nil {
-		log.Errorf("Error creating request to %s: %v", yahooCreateAccountUrl, err)
+		logger.Error("Create request failed", logKeyPhase, logPhaseBootstrap, "url", createAccountUrl, logKeyError, err)
 		return yahooBodyChecker{}, err
 	}
 
+	if err = y.limiter.Wait(ctx); err != nil {
+		return yahooBodyChecker{}, err
+	}
 	res, err := y.client.Do(req)
 	if err != nil {
-		log.Errorf("Error executing request to %s: %v", yahooCreateAccountUrl, err)
+		logger.Error("Exec request failed", logKeyPhase, logPhaseBootstrap, "url", createAccountUrl, logKeyError, err)
+		return yahooBodyChecker{}, err
+	}
+	defer closeBody(res.Body)
+	if err = checkHttpStatus(res); err != nil {
+		logger.Error("Unexpected response", logKeyPhase, logPhaseBootstrap, "url", createAccountUrl, logKeyError, err)
 		return yahooBodyChecker{}, err
 	}
-	defer res.Body.Close()
 
 	cookies := res.Header.Get("Set-Cookie")
 	if cookies == "" {
-		err := errors.New("could not detect cookies")
-		log.Error(err)
-		return yahooBodyChecker{}, err
+		logger.Warn("Session cookie missing", logKeyPhase, logPhaseBootstrap, logKeyError, ErrYahooGetCookieError)
+		return yahooBodyChecker{}, ErrYahooGetCookieError
 	}
 	arrCookies := strings.Split(cookies, ";")
 	dataBody := yahooBodyChecker{Cookie: arrCookies[0]}
 
 	htmlBytes, err := io.ReadAll(res.Body)
 	if err != nil {
-		log.Errorf("Error reading response body: %v", err)
+		logger.Error("Read response body failed", logKeyPhase, logPhaseBootstrap, logKeyError, err)
 		return yahooBodyChecker{}, err
 	}
 	html := string(htmlBytes)
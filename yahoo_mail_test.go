@@ -1,6 +1,7 @@
 package mail_checker
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -49,7 +50,7 @@ func TestGetBodyDataSuccess(t *testing.T) {
 	})
 	y := yahooMail{client: client}
 
-	bodyData, err := y.getBodyData()
+	bodyData, err := y.getBodyData(context.Background())
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -81,7 +82,7 @@ func TestGetBodyDataErrors(t *testing.T) {
 	})
 	y := yahooMail{client: client}
 
-	_, err := y.getBodyData()
+	_, err := y.getBodyData(context.Background())
 	if err == nil {
 		t.Fatalf("expected error, got nil")
 	}
@@ -97,7 +98,7 @@ func TestGetBodyDataErrors(t *testing.T) {
 	})
 	y.client = client
 
-	_, err = y.getBodyData()
+	_, err = y.getBodyData(context.Background())
 	if err == nil || err.Error() != "could not detect cookies" {
 		t.Fatalf("expected cookie detection error, got %v", err)
 	}
@@ -112,7 +113,7 @@ func TestGetBodyDataErrors(t *testing.T) {
 	})
 	y.client = client
 
-	_, err = y.getBodyData()
+	_, err = y.getBodyData(context.Background())
 	if err == nil || !strings.Contains(err.Error(), "could not detect value for acrumb") {
 		t.Fatalf("expected Acrumb detection error, got %v", err)
 	}
@@ -195,3 +196,133 @@ func TestCheck(t *testing.T) {
 		t.Fatalf("expected StatusIdCheckError, got %v", status.Id)
 	}
 }
+
+// Test CheckContext stops when the context is cancelled
+func TestCheckContextCancelled(t *testing.T) {
+	var calls int
+	client := newMockClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if err := req.Context().Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("request should carry a cancelled context")
+	})
+	y := yahooMail{client: client}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	status := y.CheckContext(ctx, "test@yahoo.com")
+	if status.Id != StatusIdCheckError {
+		t.Fatalf("expected StatusIdCheckError, got %v", status.Id)
+	}
+	if calls > 1 {
+		t.Fatalf("expected the check to stop after the first request, got %d calls", calls)
+	}
+}
+
+// Test CheckDetail reports the upstream reason code
+func TestCheckDetail(t *testing.T) {
+	client := newMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() == yahooCreateAccountUrl {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Set-Cookie": {"testCookie"}},
+				Body: io.NopCloser(strings.NewReader(`<input type="hidden" value="acrumb" name="acrumb">
+                                                       <input type="hidden" value="crumb" name="crumb">
+                                                       <input type="hidden" value="sessionIndex" name="sessionIndex">
+                                                       <input type="hidden" value="tos0" name="tos0">
+                                                       <input type="hidden" value="specId" name="specId">`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"errors": [{"name": "userId", "error": "IDENTIFIER_EXISTS"}]}`)),
+		}, nil
+	})
+	y := yahooMail{client: client}
+
+	result := y.CheckDetail(context.Background(), "test@yahoo.com")
+	if result.Status.Id != StatusIdLive {
+		t.Fatalf("expected StatusIdLive, got %v", result.Status.Id)
+	}
+	if result.Kind != MailKindYahoo {
+		t.Fatalf("expected MailKindYahoo, got %v", result.Kind)
+	}
+	if result.Reason != yahooTextDetectUnavailableMail {
+		t.Fatalf("expected reason %q, got %q", yahooTextDetectUnavailableMail, result.Reason)
+	}
+
+	// Test the error is kept when the response has no errors field
+	client = newMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() == yahooCreateAccountUrl {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Set-Cookie": {"testCookie"}},
+				Body: io.NopCloser(strings.NewReader(`<input type="hidden" value="acrumb" name="acrumb">
+                                                       <input type="hidden" value="crumb" name="crumb">
+                                                       <input type="hidden" value="sessionIndex" name="sessionIndex">
+                                                       <input type="hidden" value="tos0" name="tos0">
+                                                       <input type="hidden" value="specId" name="specId">`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"errors": null}`)),
+		}, nil
+	})
+	y.client = client
+
+	result = y.CheckDetail(context.Background(), "test@yahoo.com")
+	if result.Status.Id != StatusIdCheckError {
+		t.Fatalf("expected StatusIdCheckError, got %v", result.Status.Id)
+	}
+	if !errors.Is(result.Err, ErrYahooErrorsFieldMissing) {
+		t.Fatalf("expected ErrYahooErrorsFieldMissing, got %v", result.Err)
+	}
+}
+
+// Test usernames breaking the Yahoo rules are rejected without any request
+func TestCheckLocalPartRule(t *testing.T) {
+	client := newMockClient(func(req *http.Request) (*http.Response, error) {
+		t.Errorf("unexpected request to %s", req.URL)
+		return nil, errors.New("unexpected request")
+	})
+	y := yahooMail{client: client}
+
+	for _, email := range []string{"ab@yahoo.com", "bad-char@yahoo.com", "1abc@yahoo.com"} {
+		result := y.CheckDetail(context.Background(), email)
+		if result.Status.Id != StatusIdFormatInvalid {
+			t.Fatalf("%s: expected StatusIdFormatInvalid, got %v", email, result.Status.Id)
+		}
+	}
+}
+
+// Test upstream username rejections map to StatusIdFormatInvalid
+func TestCheckUpstreamRejected(t *testing.T) {
+	client := newMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() == yahooCreateAccountUrl {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Set-Cookie": {"testCookie"}},
+				Body: io.NopCloser(strings.NewReader(`<input type="hidden" value="acrumb" name="acrumb">
+                                                       <input type="hidden" value="crumb" name="crumb">
+                                                       <input type="hidden" value="sessionIndex" name="sessionIndex">
+                                                       <input type="hidden" value="tos0" name="tos0">
+                                                       <input type="hidden" value="specId" name="specId">`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"errors": [{"name": "userId", "error": "LENGTH_TOO_SHORT"}]}`)),
+		}, nil
+	})
+	y := yahooMail{client: client}
+
+	result := y.CheckDetail(context.Background(), "test@yahoo.com")
+	if result.Status.Id != StatusIdFormatInvalid {
+		t.Fatalf("expected StatusIdFormatInvalid, got %v", result.Status.Id)
+	}
+	if result.Reason != yahooTextDetectErrorLengthTooShort {
+		t.Fatalf("expected reason %q, got %q", yahooTextDetectErrorLengthTooShort, result.Reason)
+	}
+}